@@ -0,0 +1,64 @@
+// Package claimcheck implements the claim-check pattern: large message bodies are stored in a blob store and only a
+// reference to them travels through the broker.
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/maykonlf/pubsub"
+)
+
+// ReferenceHeader is the header carrying the key of the blob holding the message body.
+const ReferenceHeader = "x-claim-check"
+
+/*
+	New returns a claim-check middleware storing the message bodies larger than threshold bytes in the blob store.
+
+	On publish, the body is stored with a new key sent in the ReferenceHeader header instead of the body. On consume,
+	the body is fetched back before the message is delivered to the handler. Messages whose blob is missing are
+	rejected, while other store errors requeue them, so messages are only acknowledged after a successful fetch.
+*/
+func New(store BlobStore, threshold int) pubsub.Middleware {
+	return &claimCheck{store: store, threshold: threshold}
+}
+
+type claimCheck struct {
+	store     BlobStore
+	threshold int
+}
+
+func (c *claimCheck) Publish(ctx context.Context, message pubsub.Message) (pubsub.Message, error) {
+	if _, isChecked := message.HeaderString(ReferenceHeader); isChecked || len(message.Body()) <= c.threshold {
+		return message, nil
+	}
+
+	key := uuid.New().String()
+	if err := c.store.Put(ctx, key, message.Body()); err != nil {
+		return nil, fmt.Errorf("claimcheck: store body: %w", err)
+	}
+
+	return message.Clone().SetBody(nil).SetHeader(ReferenceHeader, key), nil
+}
+
+func (c *claimCheck) Consume(ctx context.Context, message pubsub.Message) (pubsub.Message, error) {
+	key, isChecked := message.HeaderString(ReferenceHeader)
+	if !isChecked {
+		return message, nil
+	}
+
+	body, err := c.store.Get(ctx, key)
+	if errors.Is(err, ErrBlobNotFound) || errors.Is(err, ErrInvalidKey) {
+		return nil, fmt.Errorf("%w: %v", pubsub.ErrUnprocessable, err)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("claimcheck: fetch body: %w", err)
+	}
+
+	headers := message.Headers()
+	delete(headers, ReferenceHeader)
+	return message.SetHeaders(headers).SetBody(body), nil
+}
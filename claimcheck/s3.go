@@ -0,0 +1,58 @@
+package claimcheck
+
+import (
+	"context"
+	"path"
+)
+
+// S3Client is the subset of an S3-compatible object storage client used by the S3 store, so applications can adapt
+// the SDK of their choice (AWS SDK, MinIO, etc) without this package depending on it.
+type S3Client interface {
+	// PutObject stores the object.
+	PutObject(ctx context.Context, bucket, key string, data []byte) error
+
+	// GetObject returns the object, or an ErrBlobNotFound wrapped error when it does not exist.
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+
+	// DeleteObject removes the object.
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// NewS3Store returns a BlobStore keeping blobs as objects of the given bucket, with keys prefixed by prefix.
+func NewS3Store(client S3Client, bucket, prefix string) BlobStore {
+	return &s3Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+type s3Store struct {
+	client S3Client
+	bucket string
+	prefix string
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, data []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	return s.client.PutObject(ctx, s.bucket, s.key(key), data)
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	return s.client.GetObject(ctx, s.bucket, s.key(key))
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	return s.client.DeleteObject(ctx, s.bucket, s.key(key))
+}
+
+func (s *s3Store) key(key string) string {
+	return path.Join(s.prefix, key)
+}
@@ -0,0 +1,165 @@
+package claimcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrBlobNotFound is returned when no blob is stored with the given key.
+var ErrBlobNotFound = errors.New("claimcheck: blob not found")
+
+// ErrInvalidKey is returned when a blob key is not a valid name (e.g. contains path separators).
+var ErrInvalidKey = errors.New("claimcheck: invalid blob key")
+
+/*
+	BlobStore stores the message bodies checked by the claim-check middleware.
+
+	Stored blobs are never deleted by the middleware, since a message may be consumed by several queues. Stores should
+	expire them after the maximum message lifetime (e.g. with a bucket lifecycle rule).
+*/
+type BlobStore interface {
+	// Put stores the data with the given key.
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get returns the data stored with the given key, or an ErrBlobNotFound wrapped error.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Delete removes the data stored with the given key.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewMemoryStore returns a BlobStore keeping blobs in memory, useful for tests and single process applications.
+func NewMemoryStore() BlobStore {
+	return &memoryStore{mutex: &sync.RWMutex{}, blobs: map[string][]byte{}}
+}
+
+type memoryStore struct {
+	mutex *sync.RWMutex
+	blobs map[string][]byte
+}
+
+func (s *memoryStore) Put(_ context.Context, key string, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.blobs[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data, found := s.blobs[key]
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrBlobNotFound, key)
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.blobs, key)
+	return nil
+}
+
+// NewFileStore returns a BlobStore keeping each blob in a file of the given directory (e.g. a shared volume), which
+// is created when missing.
+func NewFileStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("claimcheck: %w", err)
+	}
+
+	return &fileStore{dir: dir}, nil
+}
+
+type fileStore struct {
+	dir string
+}
+
+// Put writes the blob to a temporary file renamed once complete, so readers never see partial blobs.
+func (s *fileStore) Put(_ context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return fmt.Errorf("claimcheck: %w", err)
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return fmt.Errorf("claimcheck: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %q", ErrBlobNotFound, key)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("claimcheck: %w", err)
+	}
+
+	return data, nil
+}
+
+func (s *fileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("claimcheck: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
+
+// checkKey checks the key is a plain name. Keys come from consumed message headers, so they must not be able to
+// escape the store directory or prefix.
+func checkKey(key string) error {
+	if key == "" || key[0] == '.' || strings.ContainsAny(key, `/\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package claimcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		isValid bool
+	}{
+		{key: "6f1c1d0e-7c45-4c55-9a1c-5d2f7e9f0b1a", isValid: true},
+		{key: "blob.bin", isValid: true},
+		{key: "blob..bin", isValid: true},
+		{key: ""},
+		{key: "."},
+		{key: ".."},
+		{key: ".hidden"},
+		{key: "../escape"},
+		{key: "../../etc/passwd"},
+		{key: "dir/blob"},
+		{key: "/etc/passwd"},
+		{key: `..\escape`},
+		{key: `dir\blob`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			err := checkKey(test.key)
+			if test.isValid && err != nil {
+				t.Errorf("checkKey(%q) error = %v", test.key, err)
+			}
+
+			if !test.isValid && !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("checkKey(%q) error = %v, want %v", test.key, err, ErrInvalidKey)
+			}
+		})
+	}
+}
+
+func TestFileStoreRejectsTraversal(t *testing.T) {
+	root, err := ioutil.TempDir("", "claimcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	secret := filepath.Join(root, "secret")
+	if err := ioutil.WriteFile(secret, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileStore(filepath.Join(root, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := store.Get(ctx, "../secret"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Get() error = %v, want %v", err, ErrInvalidKey)
+	}
+
+	if err := store.Put(ctx, "../secret", []byte("overwritten")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put() error = %v, want %v", err, ErrInvalidKey)
+	}
+
+	if err := store.Delete(ctx, "../secret"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrInvalidKey)
+	}
+
+	if data, err := ioutil.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Errorf("file outside the store = %q, %v, want it untouched", data, err)
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "claimcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	data := []byte("large body")
+	if err := store.Put(ctx, "blob", data); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	stored, err := store.Get(ctx, "blob")
+	if err != nil || !bytes.Equal(stored, data) {
+		t.Fatalf("Get() = %q, %v, want %q", stored, err, data)
+	}
+
+	if err := store.Delete(ctx, "blob"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := store.Get(ctx, "blob"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrBlobNotFound)
+	}
+}
@@ -0,0 +1,169 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+var (
+	// Gzip compresses with gzip ("gzip" content encoding).
+	Gzip Codec = gzipCodec{}
+
+	// Zstd compresses with Zstandard ("zstd" content encoding).
+	Zstd Codec = &zstdCodec{}
+
+	// Snappy compresses with Snappy, using the block format ("snappy" content encoding).
+	Snappy Codec = snappyCodec{}
+
+	// LZ4 compresses with LZ4, using the frame format ("lz4" content encoding).
+	LZ4 Codec = lz4Codec{}
+)
+
+type gzipCodec struct{}
+
+func (gzipCodec) Encoding() string {
+	return "gzip"
+}
+
+func (gzipCodec) Compress(data []byte) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (gzipCodec) Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return readLimited(reader)
+}
+
+// zstdCodec lazily creates a shared encoder and decoder, which are safe for concurrent EncodeAll and DecodeAll calls.
+type zstdCodec struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+func (c *zstdCodec) Encoding() string {
+	return "zstd"
+}
+
+func (c *zstdCodec) init() error {
+	c.once.Do(func() {
+		if c.encoder, c.err = zstd.NewWriter(nil); c.err != nil {
+			return
+		}
+
+		c.decoder, c.err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxDecompressedSize))
+	})
+
+	return c.err
+}
+
+func (c *zstdCodec) Compress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCodec) Decompress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	decompressed, err := c.decoder.DecodeAll(data, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, errTooLarge()
+	}
+
+	return decompressed, err
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Encoding() string {
+	return "snappy"
+}
+
+func (snappyCodec) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (snappyCodec) Decompress(data []byte) ([]byte, error) {
+	size, err := snappy.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > MaxDecompressedSize {
+		return nil, errTooLarge()
+	}
+
+	return snappy.Decode(nil, data)
+}
+
+type lz4Codec struct{}
+
+func (lz4Codec) Encoding() string {
+	return "lz4"
+}
+
+func (lz4Codec) Compress(data []byte) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := lz4.NewWriter(buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (lz4Codec) Decompress(data []byte) ([]byte, error) {
+	return readLimited(lz4.NewReader(bytes.NewReader(data)))
+}
+
+// readLimited reads up to MaxDecompressedSize bytes, failing when there are more.
+func readLimited(reader io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(reader, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > MaxDecompressedSize {
+		return nil, errTooLarge()
+	}
+
+	return data, nil
+}
+
+func errTooLarge() error {
+	return fmt.Errorf("%w: more than %d bytes", ErrTooLarge, MaxDecompressedSize)
+}
@@ -0,0 +1,73 @@
+// Package compression compresses message bodies, identifying the algorithm with the message content encoding.
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MaxDecompressedSize is the max size in bytes of decompressed data (the broker default max message size), so
+// small malicious payloads can't exhaust the memory when decompressed.
+const MaxDecompressedSize = 128 << 20
+
+var (
+	// ErrUnsupportedEncoding is returned when no codec is registered for a content encoding.
+	ErrUnsupportedEncoding = errors.New("compression: unsupported content encoding")
+
+	// ErrTooLarge is returned when the decompressed data exceeds MaxDecompressedSize.
+	ErrTooLarge = errors.New("compression: decompressed data too large")
+)
+
+// Codec compresses and decompresses message bodies.
+type Codec interface {
+	// Encoding returns the content encoding identifying the codec (e.g. "gzip").
+	Encoding() string
+
+	// Compress returns the compressed data.
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress returns the decompressed data, or an ErrTooLarge wrapped error when it exceeds
+	// MaxDecompressedSize.
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	codecsMutex = &sync.RWMutex{}
+	codecs      = map[string]Codec{}
+)
+
+func init() {
+	for _, codec := range []Codec{Gzip, Zstd, Snappy, LZ4} {
+		Register(codec)
+	}
+}
+
+// Register makes a codec available for decompression by its content encoding, replacing any codec previously
+// registered for the same encoding. Gzip, Zstd, Snappy and LZ4 are registered by default.
+func Register(codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+
+	codecs[codec.Encoding()] = codec
+}
+
+// Lookup returns the codec registered for the content encoding.
+func Lookup(encoding string) (Codec, bool) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	codec, found := codecs[encoding]
+	return codec, found
+}
+
+// Decompress decompresses the data with the codec registered for the content encoding, returning an
+// ErrUnsupportedEncoding wrapped error when there is none.
+func Decompress(encoding string, data []byte) ([]byte, error) {
+	codec, found := Lookup(encoding)
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+	}
+
+	return codec.Decompress(data)
+}
@@ -0,0 +1,65 @@
+package compression_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/maykonlf/pubsub/compression"
+)
+
+var codecs = []compression.Codec{compression.Gzip, compression.Zstd, compression.Snappy, compression.LZ4}
+
+func TestCodecRoundTrip(t *testing.T) {
+	bodies := map[string][]byte{
+		"empty":      {},
+		"text":       []byte(`{"id": 1, "name": "order"}`),
+		"repetitive": bytes.Repeat([]byte("order.created "), 4096),
+	}
+
+	for _, codec := range codecs {
+		for name, body := range bodies {
+			t.Run(codec.Encoding()+" "+name, func(t *testing.T) {
+				compressed, err := codec.Compress(body)
+				if err != nil {
+					t.Fatalf("Compress() error = %v", err)
+				}
+
+				decompressed, err := compression.Decompress(codec.Encoding(), compressed)
+				if err != nil {
+					t.Fatalf("Decompress() error = %v", err)
+				}
+
+				if !bytes.Equal(decompressed, body) {
+					t.Errorf("Decompress() = %q, want %q", decompressed, body)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecDecompressTooLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compresses more than MaxDecompressedSize bytes")
+	}
+
+	bomb := make([]byte, compression.MaxDecompressedSize+1)
+	for _, codec := range codecs {
+		t.Run(codec.Encoding(), func(t *testing.T) {
+			compressed, err := codec.Compress(bomb)
+			if err != nil {
+				t.Fatalf("Compress() error = %v", err)
+			}
+
+			if _, err := codec.Decompress(compressed); !errors.Is(err, compression.ErrTooLarge) {
+				t.Errorf("Decompress() error = %v, want %v", err, compression.ErrTooLarge)
+			}
+		})
+	}
+}
+
+func TestDecompressUnsupportedEncoding(t *testing.T) {
+	if _, err := compression.Decompress("br", []byte("data")); !errors.Is(err, compression.ErrUnsupportedEncoding) {
+		t.Errorf("Decompress() error = %v, want %v", err, compression.ErrUnsupportedEncoding)
+	}
+}
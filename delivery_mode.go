@@ -0,0 +1,42 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDeliveryMode is returned when publishing a message with a delivery mode other than Transient or
+// Persistent.
+var ErrInvalidDeliveryMode = errors.New("pubsub: invalid delivery mode")
+
+// DeliveryMode defines if a message is persisted by the broker.
+type DeliveryMode uint8
+
+const (
+	// Transient messages are kept in memory only and lost if the broker restarts (default).
+	Transient DeliveryMode = 1
+
+	// Persistent messages are written to disk by the broker (when routed to durable queues) and survive restarts.
+	Persistent DeliveryMode = 2
+)
+
+// Validate returns an ErrInvalidDeliveryMode wrapped error when the mode is neither Transient nor Persistent.
+func (d DeliveryMode) Validate() error {
+	if d != Transient && d != Persistent {
+		return fmt.Errorf("%w: %d", ErrInvalidDeliveryMode, d)
+	}
+
+	return nil
+}
+
+// String returns the delivery mode name.
+func (d DeliveryMode) String() string {
+	switch d {
+	case Transient:
+		return "transient"
+	case Persistent:
+		return "persistent"
+	default:
+		return fmt.Sprintf("DeliveryMode(%d)", uint8(d))
+	}
+}
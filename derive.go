@@ -0,0 +1,116 @@
+package pubsub
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CausationChainHeader is the header listing the IDs of the messages which caused a derived message, oldest first.
+const CausationChainHeader = "x-causation-chain"
+
+// DeriveOption configures how a message is derived from its parent.
+type DeriveOption func(*deriveOptions)
+
+type deriveOptions struct {
+	headers     []string
+	dropHeaders map[string]bool
+}
+
+// WithHeaders restricts the parent headers copied to the derived message to the given keys (by default all headers
+// are copied). The causation chain and trace context headers are always kept.
+func WithHeaders(keys ...string) DeriveOption {
+	return func(o *deriveOptions) {
+		o.headers = append(o.headers, keys...)
+	}
+}
+
+// WithoutHeaders drops the given parent headers from the derived message.
+func WithoutHeaders(keys ...string) DeriveOption {
+	return func(o *deriveOptions) {
+		for _, key := range keys {
+			o.dropHeaders[key] = true
+		}
+	}
+}
+
+/*
+	Derive creates a new message from the parent one (e.g. a consumed message transformed and republished), preserving
+	its lineage: the derived message has a fresh ID, the parent correlation ID (or the parent ID when it has none), and
+	the parent ID appended to the causation chain header.
+
+	Headers, body and properties are copied from the parent, except the delay and delivery time. The derived message
+	is not bound to the parent delivery, so acknowledging it has no effect on the parent.
+*/
+func Derive(parent Message, options ...DeriveOption) Message {
+	derived := parent.Clone()
+	derived.SetID(uuid.New())
+	derived.SetCorrelationID(correlationOf(parent))
+	derived.SetHeaders(derivedHeaders(parent, options))
+	derived.SetHeader(CausationChainHeader, causationChain(parent))
+	derived.SetTimestamp(time.Now())
+	derived.SetDelay(0)
+	return derived
+}
+
+// NewReply creates a reply to the parent message (e.g. a request): like Derive, but with an empty body, no type and
+// no reply address. The reply should be published to the parent ReplyTo address.
+func NewReply(parent Message, options ...DeriveOption) Message {
+	return Derive(parent, options...).SetBody(nil).SetType("").SetReplyTo("")
+}
+
+// CausationChain returns the IDs of the messages which caused the given message, oldest first.
+func CausationChain(message Message) []string {
+	var chain []string
+	values, _ := message.GetHeader(CausationChainHeader).([]interface{})
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			chain = append(chain, id)
+		}
+	}
+
+	return chain
+}
+
+func correlationOf(parent Message) uuid.UUID {
+	if id := parent.CorrelationID(); id != uuid.Nil {
+		return id
+	}
+
+	return parent.ID()
+}
+
+func causationChain(parent Message) []interface{} {
+	chain := CausationChain(parent)
+	values := make([]interface{}, 0, len(chain)+1)
+	for _, id := range chain {
+		values = append(values, id)
+	}
+
+	return append(values, parent.ID().String())
+}
+
+func derivedHeaders(parent Message, options []DeriveOption) map[string]interface{} {
+	o := &deriveOptions{dropHeaders: map[string]bool{}}
+	for _, option := range options {
+		option(o)
+	}
+
+	headers := parent.Headers()
+	if o.headers != nil {
+		kept := make(map[string]interface{}, len(o.headers))
+		for _, key := range append(o.headers, TraceParentHeader, TraceStateHeader) {
+			if value, ok := headers[key]; ok {
+				kept[key] = value
+			}
+		}
+
+		headers = kept
+	}
+
+	for key := range o.dropHeaders {
+		delete(headers, key)
+	}
+
+	return headers
+}
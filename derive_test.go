@@ -0,0 +1,105 @@
+package pubsub_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/rabbitmq"
+)
+
+func TestDeriveLineage(t *testing.T) {
+	parentID, correlationID, rootID := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name            string
+		parent          pubsub.Message
+		wantCorrelation uuid.UUID
+		wantChain       []string
+	}{
+		{
+			name:            "root message",
+			parent:          rabbitmq.NewMessage().SetID(parentID),
+			wantCorrelation: parentID,
+			wantChain:       []string{parentID.String()},
+		},
+		{
+			name: "derived message",
+			parent: rabbitmq.NewMessage().SetID(parentID).SetCorrelationID(correlationID).
+				SetHeader(pubsub.CausationChainHeader, []interface{}{rootID.String()}),
+			wantCorrelation: correlationID,
+			wantChain:       []string{rootID.String(), parentID.String()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, derived := range map[string]pubsub.Message{
+				"Derive":   pubsub.Derive(test.parent),
+				"NewReply": pubsub.NewReply(test.parent),
+			} {
+				if derived.ID() == uuid.Nil || derived.ID() == parentID {
+					t.Errorf("%s() ID = %s, want a fresh ID", name, derived.ID())
+				}
+
+				if derived.CorrelationID() != test.wantCorrelation {
+					t.Errorf("%s() correlation ID = %s, want %s", name, derived.CorrelationID(), test.wantCorrelation)
+				}
+
+				if chain := pubsub.CausationChain(derived); !reflect.DeepEqual(chain, test.wantChain) {
+					t.Errorf("%s() causation chain = %v, want %v", name, chain, test.wantChain)
+				}
+			}
+		})
+	}
+}
+
+func TestDeriveHeaders(t *testing.T) {
+	parent := rabbitmq.NewMessage().SetHeaders(map[string]interface{}{
+		"tenant":                 "acme",
+		"attempt":                int64(2),
+		pubsub.TraceParentHeader: "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	})
+
+	tests := []struct {
+		name    string
+		options []pubsub.DeriveOption
+		want    []string
+	}{
+		{name: "all headers", want: []string{"tenant", "attempt", pubsub.TraceParentHeader}},
+		{name: "with headers", options: []pubsub.DeriveOption{pubsub.WithHeaders("tenant")},
+			want: []string{"tenant", pubsub.TraceParentHeader}},
+		{name: "without headers", options: []pubsub.DeriveOption{pubsub.WithoutHeaders("attempt")},
+			want: []string{"tenant", pubsub.TraceParentHeader}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers := pubsub.Derive(parent, test.options...).Headers()
+			if len(headers) != len(test.want)+1 {
+				t.Errorf("Derive() headers = %v, want %v and the causation chain", headers, test.want)
+			}
+
+			for _, key := range test.want {
+				if !reflect.DeepEqual(headers[key], parent.GetHeader(key)) {
+					t.Errorf("Derive() header %q = %v, want %v", key, headers[key], parent.GetHeader(key))
+				}
+			}
+		})
+	}
+}
+
+func TestNewReply(t *testing.T) {
+	parent := rabbitmq.NewMessage().SetID(uuid.New()).SetType("order.get").SetReplyTo("replies").
+		SetBody([]byte("request"))
+
+	reply := pubsub.NewReply(parent)
+	if reply.Type() != "" || reply.ReplyTo() != "" || len(reply.Body()) != 0 {
+		t.Errorf("NewReply() type = %q, reply to = %q, body = %q, want them empty", reply.Type(), reply.ReplyTo(),
+			reply.Body())
+	}
+
+	if parent.Type() != "order.get" || parent.ReplyTo() != "replies" || string(parent.Body()) != "request" {
+		t.Errorf("NewReply() changed the parent message")
+	}
+}
@@ -0,0 +1,144 @@
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/maykonlf/pubsub"
+)
+
+const (
+	// KeyIDHeader is the header carrying the ID of the key encryption key.
+	KeyIDHeader = "x-encryption-key-id"
+
+	// DataKeyHeader is the header carrying the encrypted data key.
+	DataKeyHeader = "x-encryption-data-key"
+
+	dataKeySize = 32
+)
+
+// ErrDecrypt is returned when a body or data key can't be decrypted (wrong key or tampered data).
+var ErrDecrypt = errors.New("envelope: decryption failed")
+
+/*
+	NewEncryption returns a middleware encrypting the published bodies with AES-GCM, wrapping the data keys with the
+	key encryption key identified by keyID (an AES key of 16, 24 or 32 bytes), and decrypting the consumed ones.
+
+	The keyID is only used on publish (subscribers may leave it empty). Consumed messages without encryption headers
+	are delivered as they are, while messages which can't be decrypted are rejected.
+*/
+func NewEncryption(provider KeyProvider, keyID string) pubsub.Middleware {
+	return &encryption{provider: provider, keyID: keyID}
+}
+
+type encryption struct {
+	provider KeyProvider
+	keyID    string
+}
+
+func (e *encryption) Publish(ctx context.Context, message pubsub.Message) (pubsub.Message, error) {
+	keyEncryptionKey, err := e.provider.Key(ctx, e.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := seal(keyEncryptionKey, dataKey, []byte(e.keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := seal(dataKey, message.Body(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.Clone().
+		SetBody(body).
+		SetHeader(KeyIDHeader, e.keyID).
+		SetHeader(DataKeyHeader, wrappedKey), nil
+}
+
+func (e *encryption) Consume(ctx context.Context, message pubsub.Message) (pubsub.Message, error) {
+	keyID, isEncrypted := message.HeaderString(KeyIDHeader)
+	if !isEncrypted {
+		return message, nil
+	}
+
+	wrappedKey, _ := message.GetHeader(DataKeyHeader).([]byte)
+	keyEncryptionKey, err := e.provider.Key(ctx, keyID)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, fmt.Errorf("%w: %v", pubsub.ErrUnprocessable, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := open(keyEncryptionKey, wrappedKey, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v: data key", pubsub.ErrUnprocessable, err)
+	}
+
+	body, err := open(dataKey, message.Body(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v: body", pubsub.ErrUnprocessable, err)
+	}
+
+	headers := message.Headers()
+	delete(headers, KeyIDHeader)
+	delete(headers, DataKeyHeader)
+	return message.SetHeaders(headers).SetBody(body), nil
+}
+
+// seal encrypts the plaintext with AES-GCM, returning the nonce followed by the ciphertext.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts data sealed by seal.
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
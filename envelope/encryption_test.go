@@ -0,0 +1,132 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/rabbitmq"
+)
+
+func newEncryptionProvider() KeyProvider {
+	return NewStaticKeyProvider(map[string][]byte{
+		"key-1": bytes.Repeat([]byte{1}, 32),
+		"key-2": bytes.Repeat([]byte{2}, 16),
+	})
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	middleware := NewEncryption(newEncryptionProvider(), "key-1")
+	body := []byte(`{"name":"test"}`)
+	message := rabbitmq.NewMessage().SetBody(body).SetHeader("x-custom", "value")
+
+	encrypted, err := middleware.Publish(ctx, message)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if bytes.Equal(encrypted.Body(), body) {
+		t.Fatal("Publish() left the body in plaintext")
+	}
+
+	if !bytes.Equal(message.Body(), body) {
+		t.Fatal("Publish() modified the original message")
+	}
+
+	decrypted, err := NewEncryption(newEncryptionProvider(), "").Consume(ctx, encrypted)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	if !bytes.Equal(decrypted.Body(), body) {
+		t.Errorf("Consume() body = %q, want %q", decrypted.Body(), body)
+	}
+
+	if decrypted.GetHeader(KeyIDHeader) != nil || decrypted.GetHeader(DataKeyHeader) != nil {
+		t.Error("Consume() kept the encryption headers")
+	}
+
+	if value, _ := decrypted.HeaderString("x-custom"); value != "value" {
+		t.Errorf("Consume() header x-custom = %q, want %q", value, "value")
+	}
+}
+
+func TestEncryptionConsumeUnencrypted(t *testing.T) {
+	message := rabbitmq.NewMessage().SetBody([]byte("plaintext"))
+	consumed, err := NewEncryption(newEncryptionProvider(), "").Consume(context.Background(), message)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	if string(consumed.Body()) != "plaintext" {
+		t.Errorf("Consume() body = %q, want %q", consumed.Body(), "plaintext")
+	}
+}
+
+func TestEncryptionConsumeRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(message pubsub.Message) pubsub.Message
+	}{
+		{
+			name: "tampered body",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				body := append([]byte(nil), message.Body()...)
+				body[len(body)-1] ^= 0xff
+				return message.SetBody(body)
+			},
+		},
+		{
+			name: "truncated body",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetBody(message.Body()[:4])
+			},
+		},
+		{
+			name: "tampered data key",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				dataKey := append([]byte(nil), message.GetHeader(DataKeyHeader).([]byte)...)
+				dataKey[len(dataKey)-1] ^= 0xff
+				return message.SetHeader(DataKeyHeader, dataKey)
+			},
+		},
+		{
+			name: "swapped key ID",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetHeader(KeyIDHeader, "key-2")
+			},
+		},
+		{
+			name: "unknown key ID",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetHeader(KeyIDHeader, "key-3")
+			},
+		},
+	}
+
+	ctx := context.Background()
+	middleware := NewEncryption(newEncryptionProvider(), "key-1")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encrypted, err := middleware.Publish(ctx, rabbitmq.NewMessage().SetBody([]byte("secret")))
+			if err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			_, err = middleware.Consume(ctx, test.tamper(encrypted))
+			if !errors.Is(err, pubsub.ErrUnprocessable) {
+				t.Errorf("Consume() error = %v, want %v", err, pubsub.ErrUnprocessable)
+			}
+		})
+	}
+}
+
+func TestEncryptionPublishUnknownKey(t *testing.T) {
+	_, err := NewEncryption(newEncryptionProvider(), "key-3").Publish(context.Background(), rabbitmq.NewMessage())
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Publish() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
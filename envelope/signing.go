@@ -0,0 +1,241 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/maykonlf/pubsub"
+)
+
+const (
+	// SignatureHeader is the header carrying the message signature.
+	SignatureHeader = "x-signature"
+
+	// SignatureKeyIDHeader is the header carrying the ID of the signing key.
+	SignatureKeyIDHeader = "x-signature-key-id"
+
+	// SignatureAlgorithmHeader is the header carrying the signature algorithm.
+	SignatureAlgorithmHeader = "x-signature-algorithm"
+)
+
+// ErrInvalidSignature is returned when a consumed message is unsigned or its signature doesn't match.
+var ErrInvalidSignature = errors.New("envelope: invalid signature")
+
+// Property is a message property covered by the signature, besides the body.
+type Property string
+
+const (
+	PropertyID              Property = "id"
+	PropertyCorrelationID   Property = "correlation-id"
+	PropertyType            Property = "type"
+	PropertyContentType     Property = "content-type"
+	PropertyContentEncoding Property = "content-encoding"
+	PropertyReplyTo         Property = "reply-to"
+	PropertyAppID           Property = "app-id"
+	PropertyUserID          Property = "user-id"
+
+	// PropertyTimestamp covers the message timestamp, with seconds precision (as sent by AMQP).
+	PropertyTimestamp Property = "timestamp"
+)
+
+// Signer signs and verifies data with keys identified by ID.
+type Signer interface {
+	// Algorithm returns the signature algorithm name (e.g. "hmac-sha256").
+	Algorithm() string
+
+	// Sign returns the data signature.
+	Sign(ctx context.Context, keyID string, data []byte) ([]byte, error)
+
+	// Verify returns an ErrInvalidSignature wrapped error when the signature doesn't match the data.
+	Verify(ctx context.Context, keyID string, data, signature []byte) error
+}
+
+// NewHMACSigner returns a Signer using HMAC-SHA256 with the secret keys resolved by the provider.
+func NewHMACSigner(provider KeyProvider) Signer {
+	return &hmacSigner{provider: provider}
+}
+
+type hmacSigner struct {
+	provider KeyProvider
+}
+
+func (s *hmacSigner) Algorithm() string {
+	return "hmac-sha256"
+}
+
+func (s *hmacSigner) Sign(ctx context.Context, keyID string, data []byte) ([]byte, error) {
+	key, err := s.provider.Key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (s *hmacSigner) Verify(ctx context.Context, keyID string, data, signature []byte) error {
+	expected, err := s.Sign(ctx, keyID, data)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal(expected, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// NewEd25519Signer returns a Signer using Ed25519 with the keys resolved by the provider: private keys (64 bytes) to
+// sign, and public or private keys to verify, so consumers only need the public keys.
+func NewEd25519Signer(provider KeyProvider) Signer {
+	return &ed25519Signer{provider: provider}
+}
+
+type ed25519Signer struct {
+	provider KeyProvider
+}
+
+func (s *ed25519Signer) Algorithm() string {
+	return "ed25519"
+}
+
+func (s *ed25519Signer) Sign(ctx context.Context, keyID string, data []byte) ([]byte, error) {
+	key, err := s.provider.Key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("envelope: key %q is not an ed25519 private key", keyID)
+	}
+
+	return ed25519.Sign(ed25519.PrivateKey(key), data), nil
+}
+
+func (s *ed25519Signer) Verify(ctx context.Context, keyID string, data, signature []byte) error {
+	key, err := s.provider.Key(ctx, keyID)
+	if err != nil {
+		return err
+	}
+
+	var publicKey ed25519.PublicKey
+	switch len(key) {
+	case ed25519.PrivateKeySize:
+		publicKey = ed25519.PrivateKey(key).Public().(ed25519.PublicKey)
+	case ed25519.PublicKeySize:
+		publicKey = key
+	default:
+		return fmt.Errorf("envelope: key %q is not an ed25519 key", keyID)
+	}
+
+	if !ed25519.Verify(publicKey, data, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+/*
+	NewSigning returns a middleware signing the published messages body and given properties with the key identified
+	by keyID, and verifying the consumed messages signature.
+
+	The keyID is only used on publish (subscribers may leave it empty). Consumed messages which are unsigned, signed
+	with another algorithm or whose signature doesn't match are rejected, so they are dead-lettered when the queue has a
+	dead letter exchange. Publishers and subscribers must be configured with the same properties.
+*/
+func NewSigning(signer Signer, keyID string, properties ...Property) pubsub.Middleware {
+	return &signing{signer: signer, keyID: keyID, properties: properties}
+}
+
+type signing struct {
+	signer     Signer
+	keyID      string
+	properties []Property
+}
+
+func (s *signing) Publish(ctx context.Context, message pubsub.Message) (pubsub.Message, error) {
+	signature, err := s.signer.Sign(ctx, s.keyID, s.signedData(message))
+	if err != nil {
+		return nil, err
+	}
+
+	return message.Clone().
+		SetHeader(SignatureHeader, signature).
+		SetHeader(SignatureKeyIDHeader, s.keyID).
+		SetHeader(SignatureAlgorithmHeader, s.signer.Algorithm()), nil
+}
+
+func (s *signing) Consume(ctx context.Context, message pubsub.Message) (pubsub.Message, error) {
+	signature, _ := message.GetHeader(SignatureHeader).([]byte)
+	keyID, _ := message.HeaderString(SignatureKeyIDHeader)
+	algorithm, _ := message.HeaderString(SignatureAlgorithmHeader)
+	if len(signature) == 0 || algorithm != s.signer.Algorithm() {
+		return nil, fmt.Errorf("%w: %v: unsigned or signed with %q", pubsub.ErrUnprocessable, ErrInvalidSignature,
+			algorithm)
+	}
+
+	err := s.signer.Verify(ctx, keyID, s.signedData(message), signature)
+	if errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrKeyNotFound) {
+		return nil, fmt.Errorf("%w: %v", pubsub.ErrUnprocessable, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// signedData returns the canonical encoding of the signed properties followed by the body, each value prefixed by
+// its length so values can't be shifted from one field to another.
+func (s *signing) signedData(message pubsub.Message) []byte {
+	data := &bytes.Buffer{}
+	for _, property := range s.properties {
+		writeField(data, []byte(property))
+		writeField(data, []byte(propertyValue(message, property)))
+	}
+
+	writeField(data, message.Body())
+	return data.Bytes()
+}
+
+func writeField(data *bytes.Buffer, value []byte) {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(value)))
+	data.Write(size[:])
+	data.Write(value)
+}
+
+func propertyValue(message pubsub.Message, property Property) string {
+	switch property {
+	case PropertyID:
+		return message.ID().String()
+	case PropertyCorrelationID:
+		return message.CorrelationID().String()
+	case PropertyType:
+		return message.Type()
+	case PropertyContentType:
+		return message.ContentType()
+	case PropertyContentEncoding:
+		return message.ContentEncoding()
+	case PropertyReplyTo:
+		return message.ReplyTo()
+	case PropertyAppID:
+		return message.AppID()
+	case PropertyUserID:
+		return message.UserID()
+	case PropertyTimestamp:
+		return strconv.FormatInt(message.Timestamp().Unix(), 10)
+	default:
+		return ""
+	}
+}
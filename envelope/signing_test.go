@@ -0,0 +1,185 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/rabbitmq"
+)
+
+func newSigners(t *testing.T) (map[string]Signer, map[string]Signer) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := NewStaticKeyProvider(map[string][]byte{"key-1": []byte("secret-1"), "key-2": []byte("secret-2")})
+	publishers := map[string]Signer{
+		"hmac":    NewHMACSigner(secrets),
+		"ed25519": NewEd25519Signer(NewStaticKeyProvider(map[string][]byte{"key-1": privateKey})),
+	}
+
+	subscribers := map[string]Signer{
+		"hmac":    NewHMACSigner(secrets),
+		"ed25519": NewEd25519Signer(NewStaticKeyProvider(map[string][]byte{"key-1": publicKey})),
+	}
+
+	return publishers, subscribers
+}
+
+func newSignedMessage() pubsub.Message {
+	return rabbitmq.NewMessage().
+		SetBody([]byte(`{"name":"test"}`)).
+		SetType("user.created").
+		SetCorrelationID(uuid.New())
+}
+
+func TestSigningRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	publishers, subscribers := newSigners(t)
+	for name := range publishers {
+		t.Run(name, func(t *testing.T) {
+			message := newSignedMessage()
+			signed, err := NewSigning(publishers[name], "key-1", PropertyType, PropertyCorrelationID).
+				Publish(ctx, message)
+			if err != nil {
+				t.Fatalf("Publish() error = %v", err)
+			}
+
+			if message.GetHeader(SignatureHeader) != nil {
+				t.Fatal("Publish() modified the original message")
+			}
+
+			verified, err := NewSigning(subscribers[name], "", PropertyType, PropertyCorrelationID).
+				Consume(ctx, signed)
+			if err != nil {
+				t.Fatalf("Consume() error = %v", err)
+			}
+
+			if !bytes.Equal(verified.Body(), message.Body()) {
+				t.Errorf("Consume() body = %q, want %q", verified.Body(), message.Body())
+			}
+		})
+	}
+}
+
+func TestSigningConsumeRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(message pubsub.Message) pubsub.Message
+	}{
+		{
+			name: "tampered body",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetBody([]byte(`{"name":"tampered"}`))
+			},
+		},
+		{
+			name: "tampered signed property",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetType("user.deleted")
+			},
+		},
+		{
+			name: "tampered signature",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				signature := append([]byte(nil), message.GetHeader(SignatureHeader).([]byte)...)
+				signature[0] ^= 0xff
+				return message.SetHeader(SignatureHeader, signature)
+			},
+		},
+		{
+			name: "swapped key ID",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetHeader(SignatureKeyIDHeader, "key-2")
+			},
+		},
+		{
+			name: "wrong algorithm",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				return message.SetHeader(SignatureAlgorithmHeader, "none")
+			},
+		},
+		{
+			name: "unsigned",
+			tamper: func(message pubsub.Message) pubsub.Message {
+				headers := message.Headers()
+				delete(headers, SignatureHeader)
+				return message.SetHeaders(headers)
+			},
+		},
+	}
+
+	ctx := context.Background()
+	publishers, subscribers := newSigners(t)
+	for name := range publishers {
+		for _, test := range tests {
+			t.Run(name+" "+test.name, func(t *testing.T) {
+				signed, err := NewSigning(publishers[name], "key-1", PropertyType).Publish(ctx, newSignedMessage())
+				if err != nil {
+					t.Fatalf("Publish() error = %v", err)
+				}
+
+				_, err = NewSigning(subscribers[name], "", PropertyType).Consume(ctx, test.tamper(signed))
+				if !errors.Is(err, pubsub.ErrUnprocessable) {
+					t.Errorf("Consume() error = %v, want %v", err, pubsub.ErrUnprocessable)
+				}
+			})
+		}
+	}
+}
+
+func TestSigningAlgorithmMismatch(t *testing.T) {
+	ctx := context.Background()
+	publishers, subscribers := newSigners(t)
+	signed, err := NewSigning(publishers["hmac"], "key-1").Publish(ctx, newSignedMessage())
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	_, err = NewSigning(subscribers["ed25519"], "").Consume(ctx, signed)
+	if !errors.Is(err, pubsub.ErrUnprocessable) {
+		t.Errorf("Consume() error = %v, want %v", err, pubsub.ErrUnprocessable)
+	}
+}
+
+func TestEd25519SignWithPublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := NewEd25519Signer(NewStaticKeyProvider(map[string][]byte{"key-1": publicKey}))
+	if _, err := signer.Sign(context.Background(), "key-1", []byte("data")); err == nil {
+		t.Error("Sign() with a public key succeeded")
+	}
+}
+
+func TestEd25519VerifyWithPublicKey(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	data := []byte("data")
+	signature, err := NewEd25519Signer(NewStaticKeyProvider(map[string][]byte{"key-1": privateKey})).
+		Sign(ctx, "key-1", data)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	verifier := NewEd25519Signer(NewStaticKeyProvider(map[string][]byte{"key-1": publicKey}))
+	if err := verifier.Verify(ctx, "key-1", data, signature); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	if err := verifier.Verify(ctx, "key-1", []byte("other"), signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
@@ -4,5 +4,10 @@ go 1.15
 
 require (
 	github.com/google/uuid v1.1.2
+	github.com/klauspost/compress v1.15.15
+	github.com/pierrec/lz4/v4 v4.1.17
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/streadway/amqp v1.0.0
+	google.golang.org/protobuf v1.28.1
+	gopkg.in/yaml.v3 v3.0.1
 )
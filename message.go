@@ -41,9 +41,20 @@ type Message interface {
 	*/
 	Reject() error
 
+	/*
+		Clone returns a deep copy of the message (headers and body included) with the same ID, which can be safely
+		changed and republished or forwarded without affecting the original message.
+
+		The clone is not bound to the original delivery, so it can't be acknowledged.
+	*/
+	Clone() Message
+
 	// ID returns the unique message ID.
 	ID() uuid.UUID
 
+	// SetID replaces the message ID.
+	SetID(id uuid.UUID) Message
+
 	/*
 		CorrelationID returns the message correlation id when is set.
 
@@ -59,7 +70,7 @@ type Message interface {
 	SetCorrelationID(id uuid.UUID) Message
 
 	/*
-		Headers returns the message headers.
+		Headers returns a copy of the message headers.
 
 		Useful when you need to send/receive additional information of with a message, but dont want to put it on
 		the message body.
@@ -67,7 +78,7 @@ type Message interface {
 	Headers() map[string]interface{}
 
 	/*
-		SetHeaders set message headers.
+		SetHeaders set message headers (copying the given map, so later changes to it don't affect the message).
 
 		Useful when you need to send/receive additional information of with a message, but dont want to put it on
 		the message body.
@@ -82,6 +93,21 @@ type Message interface {
 	*/
 	GetHeader(key string) interface{}
 
+	/*
+		HeaderString returns the value of a string message header, and false when not set or not a string.
+	*/
+	HeaderString(key string) (string, bool)
+
+	/*
+		HeaderInt64 returns the value of an integer message header, and false when not set or not an integer.
+	*/
+	HeaderInt64(key string) (int64, bool)
+
+	/*
+		HeaderTime returns the value of a timestamp message header, and false when not set or not a timestamp.
+	*/
+	HeaderTime(key string) (time.Time, bool)
+
 	/*
 		SetHeader put some value to a specific message header.
 
@@ -132,16 +158,27 @@ type Message interface {
 
 	/*
 		DeliveryMode returns message delivery mode:
-		1 - non-persistent (default)
-		2 - persistent
+		1 - non-persistent (Transient, default)
+		2 - persistent (Persistent)
 
 		A persistent delivery means that if the broker server fails the message will not be lost (for rabbitmq only) but
 		it will make the pubsub operations for this slower.
 	*/
-	DeliveryMode() uint8
+	DeliveryMode() DeliveryMode
 
 	/*
-		SetDeliveryModePersistent set message delivery mode to persistent.
+		HasDeliveryMode returns true when the delivery mode was explicitly set (or received), so publishers can apply
+		their default delivery mode to the other messages.
+	*/
+	HasDeliveryMode() bool
+
+	/*
+		SetDeliveryMode set message delivery mode (Transient or Persistent). Invalid modes are rejected on publish.
+	*/
+	SetDeliveryMode(mode DeliveryMode) Message
+
+	/*
+		SetDeliveryModePersistent set message delivery mode to persistent. Same as SetDeliveryMode(Persistent).
 
 		A persistent delivery means that if the broker server fails the message will not be lost (for rabbitmq only) but
 		it will make the pubsub operations for this slower.
@@ -158,9 +195,22 @@ type Message interface {
 
 		Messages without a priority are treated as if their priority were 0. If published on a non-priority queue
 		this value is ignored. Is recommended to use values between 1 and 10.
+
+		Publishers clamp or reject the priorities above the target queue max priority, when known.
 	*/
 	SetPriority(priority uint8) Message
 
+	/*
+		PriorityLevel returns the message named priority level, or 0 when none is set.
+	*/
+	PriorityLevel() PriorityLevel
+
+	/*
+		SetPriorityLevel defines the message priority by a named level (e.g. PriorityHigh), which the publisher maps
+		onto the target queue priority range. It replaces the priority set by SetPriority.
+	*/
+	SetPriorityLevel(level PriorityLevel) Message
+
 	/*
 		ReplyTo returns the address to reply to (application usage only).
 	*/
@@ -183,11 +233,41 @@ type Message interface {
 	*/
 	SetExpiration(expiration time.Duration) Message
 
+	/*
+		Delay returns how long the message delivery is delayed after it is published (zero when not delayed).
+
+		When a delivery time is set (see SetDeliverAt) the delay is the remaining time until it.
+	*/
+	Delay() time.Duration
+
+	/*
+		SetDelay delays the message delivery by the given duration after it is published.
+
+		Useful for reminders, timeouts and retries with backoff.
+	*/
+	SetDelay(delay time.Duration) Message
+
+	/*
+		DeliverAt returns the time the message should be delivered at (zero when not scheduled).
+	*/
+	DeliverAt() time.Time
+
+	/*
+		SetDeliverAt schedules the message delivery to the given time. Messages scheduled in the past are delivered
+		right away.
+	*/
+	SetDeliverAt(deliverAt time.Time) Message
+
 	/*
 		Type returns message type (application usage only).
 	*/
 	Type() string
 
+	/*
+		RoutingKey returns the routing key the message was published with, for consumed messages (empty otherwise).
+	*/
+	RoutingKey() string
+
 	/*
 		SetType defines message type (application usage only).
 	*/
@@ -0,0 +1,34 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnprocessable is wrapped by the middleware errors meaning a consumed message can never be processed (e.g. a
+// corrupted body or an invalid signature), so subscribers reject it instead of requeuing it.
+var ErrUnprocessable = errors.New("pubsub: unprocessable message")
+
+/*
+	Middleware transforms the messages on publish and on consume, e.g. to store large bodies elsewhere or to encrypt
+	them.
+
+	Publishers apply their middlewares in the order they were added and subscribers apply them in reverse order, so the
+	transformations are undone symmetrically.
+*/
+type Middleware interface {
+	/*
+		Publish transforms a message before it is published. It must not change the given message, which belongs to
+		the caller, but return a changed clone (see Message.Clone) or the message itself when unchanged.
+	*/
+	Publish(ctx context.Context, message Message) (Message, error)
+
+	/*
+		Consume transforms a consumed message before it is delivered to the handler. Clones can't be acknowledged, so
+		it should change and return the given message.
+
+		Returning an ErrUnprocessable wrapped error rejects the message, while any other error requeues it (e.g. a
+		temporarily unavailable dependency).
+	*/
+	Consume(ctx context.Context, message Message) (Message, error)
+}
@@ -0,0 +1,51 @@
+package pubsub
+
+import "fmt"
+
+// DefaultMaxPriority is the max priority the priority levels are mapped onto when the target queue max priority is
+// unknown, as RabbitMQ recommends priority queues to use up to 10 priorities.
+const DefaultMaxPriority uint8 = 10
+
+// PriorityLevel is a named message priority, mapped onto the target queue priority range when published.
+type PriorityLevel uint8
+
+const (
+	// PriorityLow maps to the lowest priority (0).
+	PriorityLow PriorityLevel = iota + 1
+
+	// PriorityNormal maps to one third of the max priority.
+	PriorityNormal
+
+	// PriorityHigh maps to two thirds of the max priority.
+	PriorityHigh
+
+	// PriorityCritical maps to the max priority.
+	PriorityCritical
+)
+
+// Priority returns the priority of the level in the range 0 to maxPriority (e.g. 0, 3, 7 and 10 for a max priority
+// of 10), or 0 for unknown levels.
+func (l PriorityLevel) Priority(maxPriority uint8) uint8 {
+	if l < PriorityLow || l > PriorityCritical {
+		return 0
+	}
+
+	step := int(l - PriorityLow)
+	return uint8((int(maxPriority)*step + 1) / int(PriorityCritical-PriorityLow))
+}
+
+// String returns the priority level name.
+func (l PriorityLevel) String() string {
+	switch l {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("PriorityLevel(%d)", uint8(l))
+	}
+}
@@ -0,0 +1,37 @@
+package pubsub
+
+import "testing"
+
+func TestPriorityLevelPriority(t *testing.T) {
+	tests := []struct {
+		maxPriority uint8
+		want        map[PriorityLevel]uint8
+	}{
+		{maxPriority: 10, want: map[PriorityLevel]uint8{PriorityLow: 0, PriorityNormal: 3, PriorityHigh: 7,
+			PriorityCritical: 10}},
+		{maxPriority: 255, want: map[PriorityLevel]uint8{PriorityLow: 0, PriorityNormal: 85, PriorityHigh: 170,
+			PriorityCritical: 255}},
+		{maxPriority: 5, want: map[PriorityLevel]uint8{PriorityLow: 0, PriorityNormal: 2, PriorityHigh: 3,
+			PriorityCritical: 5}},
+		{maxPriority: 1, want: map[PriorityLevel]uint8{PriorityLow: 0, PriorityNormal: 0, PriorityHigh: 1,
+			PriorityCritical: 1}},
+		{maxPriority: 0, want: map[PriorityLevel]uint8{PriorityLow: 0, PriorityNormal: 0, PriorityHigh: 0,
+			PriorityCritical: 0}},
+	}
+
+	for _, test := range tests {
+		for level, want := range test.want {
+			if got := level.Priority(test.maxPriority); got != want {
+				t.Errorf("%s.Priority(%d) = %d, want %d", level, test.maxPriority, got, want)
+			}
+		}
+	}
+}
+
+func TestPriorityLevelPriorityUnknown(t *testing.T) {
+	for _, level := range []PriorityLevel{0, PriorityCritical + 1, 255} {
+		if got := level.Priority(10); got != 0 {
+			t.Errorf("%s.Priority(10) = %d, want 0", level, got)
+		}
+	}
+}
@@ -1,6 +1,15 @@
 package pubsub
 
+import "context"
+
 type Publisher interface {
 	// Publish publishes a message to a topic (and optionally to a routing key).
 	Publish(message Message, topic string, routingKey ...string) error
+
+	/*
+		PublishContext publishes a message to a topic (and optionally to a routing key), giving up when ctx is done.
+
+		The trace context carried by ctx (see ContextWithTraceParent) is propagated into the message headers.
+	*/
+	PublishContext(ctx context.Context, message Message, topic string, routingKey ...string) error
 }
@@ -0,0 +1,12 @@
+package connection
+
+// externalAuth is the SASL EXTERNAL mechanism, which authenticates the client by its TLS certificate.
+type externalAuth struct{}
+
+func (externalAuth) Mechanism() string {
+	return "EXTERNAL"
+}
+
+func (externalAuth) Response() string {
+	return ""
+}
@@ -0,0 +1,132 @@
+package connection
+
+import (
+	"math/rand"
+	"time"
+)
+
+/*
+	BackoffStrategy computes the delay before each connection attempt.
+
+	Implementations must be safe for concurrent use, since the same Options (and strategy) may be shared by many
+	connections.
+*/
+type BackoffStrategy interface {
+	/*
+		Next returns the delay before the given retry attempt (starting at 1) and the delay used before the previous
+		attempt (zero on the first attempt).
+
+		Returning false gives up reconnecting, which is a terminal state of the connection.
+	*/
+	Next(attempt int, previous time.Duration) (time.Duration, bool)
+}
+
+// NewExponentialBackoff returns a strategy doubling the delay on each attempt, from initial up to max.
+func NewExponentialBackoff(initial, max time.Duration) BackoffStrategy {
+	return &exponentialBackoff{initial: initial, max: max}
+}
+
+type exponentialBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+func (b *exponentialBackoff) Next(attempt int, _ time.Duration) (time.Duration, bool) {
+	return exponentialDelay(b.initial, b.max, attempt), true
+}
+
+/*
+	NewFullJitterBackoff returns a strategy waiting a random delay between zero and the exponential delay (doubled on
+	each attempt, from initial up to max).
+
+	Randomizing the whole delay spreads the reconnections of many clients after a broker restart.
+*/
+func NewFullJitterBackoff(initial, max time.Duration) BackoffStrategy {
+	return &fullJitterBackoff{initial: initial, max: max}
+}
+
+type fullJitterBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+func (b *fullJitterBackoff) Next(attempt int, _ time.Duration) (time.Duration, bool) {
+	return randomDuration(0, exponentialDelay(b.initial, b.max, attempt)), true
+}
+
+/*
+	NewDecorrelatedJitterBackoff returns a strategy waiting a random delay between initial and three times the
+	previous delay, capped to max.
+*/
+func NewDecorrelatedJitterBackoff(initial, max time.Duration) BackoffStrategy {
+	return &decorrelatedJitterBackoff{initial: initial, max: max}
+}
+
+type decorrelatedJitterBackoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+func (b *decorrelatedJitterBackoff) Next(_ int, previous time.Duration) (time.Duration, bool) {
+	if previous < b.initial {
+		previous = b.initial
+	}
+
+	return minDuration(randomDuration(b.initial, previous*3), b.max), true
+}
+
+// NewConstantBackoff returns a strategy always waiting the same interval between attempts.
+func NewConstantBackoff(interval time.Duration) BackoffStrategy {
+	return &constantBackoff{interval: interval}
+}
+
+type constantBackoff struct {
+	interval time.Duration
+}
+
+func (b *constantBackoff) Next(int, time.Duration) (time.Duration, bool) {
+	return b.interval, true
+}
+
+// NewCappedBackoff wraps a strategy giving up after maxAttempts failed retries.
+func NewCappedBackoff(strategy BackoffStrategy, maxAttempts int) BackoffStrategy {
+	return &cappedBackoff{strategy: strategy, maxAttempts: maxAttempts}
+}
+
+type cappedBackoff struct {
+	strategy    BackoffStrategy
+	maxAttempts int
+}
+
+func (b *cappedBackoff) Next(attempt int, previous time.Duration) (time.Duration, bool) {
+	if attempt > b.maxAttempts {
+		return 0, false
+	}
+
+	return b.strategy.Next(attempt, previous)
+}
+
+func exponentialDelay(initial, max time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+
+	return minDuration(delay, max)
+}
+
+func randomDuration(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+
+	return min + time.Duration(rand.Int63n(int64(max-min)))
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,148 @@
+package connection
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial time.Duration
+		max     time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", initial: time.Second, max: time.Minute, attempt: 1, want: time.Second},
+		{name: "second attempt", initial: time.Second, max: time.Minute, attempt: 2, want: 2 * time.Second},
+		{name: "fifth attempt", initial: time.Second, max: time.Minute, attempt: 5, want: 16 * time.Second},
+		{name: "capped", initial: time.Second, max: 10 * time.Second, attempt: 5, want: 10 * time.Second},
+		{name: "many attempts", initial: time.Second, max: time.Minute, attempt: 1000, want: time.Minute},
+		{name: "initial above max", initial: time.Minute, max: time.Second, attempt: 1, want: time.Second},
+		{name: "zero attempt", initial: time.Second, max: time.Minute, attempt: 0, want: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := exponentialDelay(test.initial, test.max, test.attempt); got != test.want {
+				t.Errorf("exponentialDelay(%v, %v, %d) = %v, want %v", test.initial, test.max, test.attempt, got,
+					test.want)
+			}
+		})
+	}
+}
+
+func TestBackoffStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy BackoffStrategy
+		attempt  int
+		previous time.Duration
+		min      time.Duration
+		max      time.Duration
+	}{
+		{
+			name:     "exponential",
+			strategy: NewExponentialBackoff(time.Second, time.Minute),
+			attempt:  3,
+			min:      4 * time.Second,
+			max:      4 * time.Second,
+		},
+		{
+			name:     "exponential capped",
+			strategy: NewExponentialBackoff(time.Second, 3*time.Second),
+			attempt:  3,
+			min:      3 * time.Second,
+			max:      3 * time.Second,
+		},
+		{
+			name:     "full jitter",
+			strategy: NewFullJitterBackoff(time.Second, time.Minute),
+			attempt:  3,
+			min:      0,
+			max:      4 * time.Second,
+		},
+		{
+			name:     "full jitter capped",
+			strategy: NewFullJitterBackoff(time.Second, 2*time.Second),
+			attempt:  10,
+			min:      0,
+			max:      2 * time.Second,
+		},
+		{
+			name:     "decorrelated jitter first attempt",
+			strategy: NewDecorrelatedJitterBackoff(time.Second, time.Minute),
+			attempt:  1,
+			min:      time.Second,
+			max:      3 * time.Second,
+		},
+		{
+			name:     "decorrelated jitter",
+			strategy: NewDecorrelatedJitterBackoff(time.Second, time.Minute),
+			attempt:  2,
+			previous: 5 * time.Second,
+			min:      time.Second,
+			max:      15 * time.Second,
+		},
+		{
+			name:     "decorrelated jitter capped",
+			strategy: NewDecorrelatedJitterBackoff(time.Second, 2*time.Second),
+			attempt:  2,
+			previous: time.Minute,
+			min:      time.Second,
+			max:      2 * time.Second,
+		},
+		{
+			name:     "constant",
+			strategy: NewConstantBackoff(time.Second),
+			attempt:  100,
+			previous: time.Minute,
+			min:      time.Second,
+			max:      time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay, ok := test.strategy.Next(test.attempt, test.previous)
+				if !ok {
+					t.Fatalf("Next(%d, %v) gave up", test.attempt, test.previous)
+				}
+
+				if delay < test.min || delay > test.max {
+					t.Fatalf("Next(%d, %v) = %v, want between %v and %v", test.attempt, test.previous, delay,
+						test.min, test.max)
+				}
+			}
+		})
+	}
+}
+
+func TestCappedBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		attempt     int
+		wantOK      bool
+	}{
+		{name: "first attempt", maxAttempts: 3, attempt: 1, wantOK: true},
+		{name: "last attempt", maxAttempts: 3, attempt: 3, wantOK: true},
+		{name: "above max attempts", maxAttempts: 3, attempt: 4, wantOK: false},
+		{name: "no retries", maxAttempts: 0, attempt: 1, wantOK: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			strategy := NewCappedBackoff(NewConstantBackoff(time.Second), test.maxAttempts)
+			delay, ok := strategy.Next(test.attempt, 0)
+			if ok != test.wantOK {
+				t.Fatalf("Next(%d, 0) ok = %v, want %v", test.attempt, ok, test.wantOK)
+			}
+
+			if ok && delay != time.Second {
+				t.Errorf("Next(%d, 0) = %v, want %v", test.attempt, delay, time.Second)
+			}
+		})
+	}
+}
@@ -1,119 +1,363 @@
 package connection
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/streadway/amqp"
 )
 
+// ErrNoEndpoints is returned when the connection options have no URI.
+var ErrNoEndpoints = errors.New("connection: no endpoint URI configured")
+
+// ErrClosed is returned when reconnecting a connection closed by the client.
+var ErrClosed = errors.New("connection: closed")
+
+// ErrGaveUp is returned when the backoff strategy gives up connecting to the broker, or a reconnect hook fails with a
+// channel exception.
+var ErrGaveUp = errors.New("connection: gave up connecting")
+
 type Connection interface {
 	GetConn() *amqp.Connection
 	GetChannel() *amqp.Channel
-	SetReconnectHooks(...func())
+
+	/*
+		SetReconnectHooks set the functions called after each reconnection, and after the channel is re-opened.
+
+		A hook failing with a channel exception (e.g. a declaration refused with PRECONDITION_FAILED) gives up
+		reconnecting, since the broker would close the re-opened channel again. Other hook errors are ignored.
+	*/
+	SetReconnectHooks(...func() error)
+
+	// GetEndpoint returns the URI (without password) of the endpoint the connection is established with.
+	GetEndpoint() string
+
+	// Done returns a channel closed when the backoff strategy gives up reconnecting.
+	Done() <-chan struct{}
+
+	// Err returns nil while the connection is alive or reconnecting, or an ErrGaveUp wrapped error once it gave up.
+	Err() error
+
+	// Close gracefully closes the connection, without reconnecting.
+	Close() error
+
+	// IsBlocked returns true while the broker blocks publishing on the connection (connection.blocked or
+	// channel.flow), e.g. because of a memory or disk alarm.
+	IsBlocked() bool
+
+	// BlockedReason returns the reason given by the broker for blocking the connection.
+	BlockedReason() string
+
+	// WaitUnblocked waits until the broker unblocks the connection or ctx is done.
+	WaitUnblocked(ctx context.Context) error
 }
 
+/*
+	connection keeps an AMQP connection and channel open.
+
+	Connection and channel closes are watched independently: when the connection is lost a new one is dialed, and when
+	only the channel is closed by the server (e.g. publishing to a missing exchange) a new channel is opened on the
+	same connection, after the backoff delay. Both trigger the reconnect hooks. The state swapped on reconnection is
+	guarded by mutex, so getters are safe to call concurrently with the reconnection goroutine.
+*/
 type connection struct {
-	options                   *Options
-	connection                *amqp.Connection
-	channel                   *amqp.Channel
-	disconnectionErrorChannel chan *amqp.Error
-	reconnectHooks            []func()
-	backoffInterval           time.Duration
-	initialBackoffInterval    time.Duration
-	maxBackoffInterval        time.Duration
+	*flowControl
+	mutex           *sync.RWMutex
+	options         *Options
+	endpoints       *endpoints
+	endpoint        string
+	connection      *amqp.Connection
+	channel         *amqp.Channel
+	reconnectHooks  []func() error
+	backoffStrategy BackoffStrategy
+	done            chan struct{}
+	err             error
+	isClosed        bool
 }
 
-func (c *connection) SetReconnectHooks(hooks ...func()) {
+func (c *connection) SetReconnectHooks(hooks ...func() error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	c.reconnectHooks = hooks
 }
 
+// NewConnection connects to the broker, retrying according to the backoff strategy. It panics with an ErrGaveUp
+// wrapped error when the strategy gives up before the first connection is established.
 func NewConnection(options *Options) Connection {
+	conn, err := NewConnectionContext(context.Background(), options)
+	panicOnError(err)
+	return conn
+}
+
+// NewConnectionContext connects to the broker, retrying according to the backoff strategy until the first connection
+// is established, the strategy gives up (returning an ErrGaveUp wrapped error) or ctx is done.
+func NewConnectionContext(ctx context.Context, options *Options) (Connection, error) {
 	conn := &connection{
-		options:                   options,
-		disconnectionErrorChannel: make(chan *amqp.Error),
-		backoffInterval:           options.getInitialBackoffInterval(),
-		initialBackoffInterval:    options.getInitialBackoffInterval(),
-		maxBackoffInterval:        options.getMaxBackoffInterval(),
+		flowControl:     newFlowControl(),
+		mutex:           &sync.RWMutex{},
+		options:         options,
+		endpoints:       newEndpoints(options.getURIs(), options.EndpointSelection),
+		backoffStrategy: options.getBackoffStrategy(),
+		done:            make(chan struct{}),
 	}
-	conn.connect()
-	return conn
+
+	if err := conn.connectWithRetry(ctx, 0, nil); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// connectWithRetry tries to connect until it succeeds, the backoff strategy gives up or ctx is done. Attempts greater
+// than zero wait for the backoff delay before connecting.
+func (c *connection) connectWithRetry(ctx context.Context, attempt int, lastErr error) error {
+	var delay time.Duration
+	for ; ; attempt++ {
+		if attempt > 0 {
+			var shouldRetry bool
+			if delay, shouldRetry = c.backoffStrategy.Next(attempt, delay); !shouldRetry {
+				return c.giveUp(lastErr)
+			}
+
+			if err := sleepContext(ctx, delay); err != nil {
+				return err
+			}
+		}
+
+		if c.isGracefullyClosed() {
+			return ErrClosed
+		}
+
+		if lastErr = c.connect(); lastErr == nil {
+			return nil
+		}
+	}
+}
+
+func (c *connection) connect() error {
+	conn, uri, err := c.dial()
+	if err != nil {
+		return err
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	c.mutex.Lock()
+	c.connection = conn
+	c.channel = channel
+	c.endpoint = uri
+	c.mutex.Unlock()
+
+	go c.watch(conn, channel)
+	go c.watchBlocked(conn)
+	go c.watchFlow(channel)
+	return nil
+}
+
+func (c *connection) giveUp(lastErr error) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.err = fmt.Errorf("%w: %v", ErrGaveUp, lastErr)
+	close(c.done)
+	return c.err
 }
 
-func (c *connection) connect() {
-	panicOnError(c.dial())
-	panicOnError(c.openChannel())
-	c.reconnectOnDisconnection()
+func (c *connection) Done() <-chan struct{} {
+	return c.done
 }
 
-func (c *connection) reconnectOnDisconnection() {
-	go c.subscribeDisconnectionEvent()
-	go c.watchDisconnectionAndReconnect()
+func (c *connection) Err() error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.err
+}
+
+func (c *connection) Close() error {
+	c.mutex.Lock()
+	c.isClosed = true
+	conn := c.connection
+	c.mutex.Unlock()
+
+	return conn.Close()
+}
+
+func (c *connection) isGracefullyClosed() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.isClosed
 }
 
 func (c *connection) GetConn() *amqp.Connection {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.connection
 }
 
 func (c *connection) GetChannel() *amqp.Channel {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.channel
 }
 
-func (c *connection) dial() (err error) {
-	c.connection, err = amqp.Dial(c.options.URI)
-	return err
+func (c *connection) GetEndpoint() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return redactURI(c.endpoint)
+}
+
+func (c *connection) dial() (*amqp.Connection, string, error) {
+	err := ErrNoEndpoints
+	for _, index := range c.endpoints.order() {
+		uri := c.endpoints.get(index)
+
+		var conn *amqp.Connection
+		if conn, err = c.dialEndpoint(uri); err == nil {
+			c.endpoints.connected(index)
+			return conn, uri, nil
+		}
+	}
+
+	return nil, "", err
 }
 
-func (c *connection) openChannel() (err error) {
-	c.channel, err = c.connection.Channel()
+func (c *connection) dialEndpoint(uri string) (*amqp.Connection, error) {
+	config, err := c.options.getConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c.resetBackoffInterval()
-	return nil
+	return amqp.DialConfig(uri, config)
 }
 
-func (c *connection) subscribeDisconnectionEvent() {
-	err := <-c.connection.NotifyClose(make(chan *amqp.Error))
-	c.disconnectionErrorChannel <- err
+/*
+	watch waits for the connection or channel to be closed, then reconnects or re-opens the channel. A nil close error
+	means a graceful close requested by the client, which is not recovered.
+
+	Channel re-opens follow the backoff strategy, whose attempts are reset once the reconnect hooks succeed.
+*/
+func (c *connection) watch(conn *amqp.Connection, channel *amqp.Channel) {
+	connectionClose := conn.NotifyClose(make(chan *amqp.Error, 1))
+	channelClose := channel.NotifyClose(make(chan *amqp.Error, 1))
+	var attempt int
+	var delay time.Duration
+	for {
+		select {
+		case err := <-connectionClose:
+			if err != nil {
+				c.reconnectAndTriggerHooks(err)
+			}
+
+			return
+
+		case err := <-channelClose:
+			if err == nil || conn.IsClosed() {
+				channelClose = nil
+				continue
+			}
+
+			attempt++
+			var shouldRetry bool
+			if delay, shouldRetry = c.backoffStrategy.Next(attempt, delay); !shouldRetry {
+				c.abort(conn, err)
+				return
+			}
+
+			time.Sleep(delay)
+			if c.isGracefullyClosed() || conn.IsClosed() {
+				channelClose = nil
+				continue
+			}
+
+			reopened, reopenErr := c.reopenChannel(conn)
+			if reopenErr != nil {
+				_ = conn.Close()
+				c.reconnectAndTriggerHooks(reopenErr)
+				return
+			}
+
+			channelClose = reopened.NotifyClose(make(chan *amqp.Error, 1))
+			if hookErr := c.triggerReconnectHooks(); hookErr != nil {
+				c.abort(conn, hookErr)
+				return
+			}
+
+			attempt, delay = 0, 0
+		}
+	}
 }
 
-func (c *connection) watchDisconnectionAndReconnect() {
-	err := <-c.disconnectionErrorChannel
+func (c *connection) reopenChannel(conn *amqp.Connection) (*amqp.Channel, error) {
+	channel, err := conn.Channel()
 	if err != nil {
-		c.reconnectAndTriggerHooks()
+		return nil, err
 	}
+
+	c.mutex.Lock()
+	c.channel = channel
+	c.mutex.Unlock()
+
+	go c.watchFlow(channel)
+	return channel, nil
 }
 
-func (c *connection) reconnectAndTriggerHooks() {
-	c.waitForRetryAndIncreaseBackoffDuration()
-	c.connect()
-	if len(c.reconnectHooks) > 0 {
-		c.triggerReconnectHooks()
+func (c *connection) reconnectAndTriggerHooks(disconnectionErr error) {
+	if c.connectWithRetry(context.Background(), 1, disconnectionErr) != nil {
+		return
 	}
-}
 
-func (c *connection) triggerReconnectHooks() {
-	for _, hook := range c.reconnectHooks {
-		hook()
+	if err := c.triggerReconnectHooks(); err != nil {
+		c.abort(c.GetConn(), err)
 	}
 }
 
-func (c *connection) waitForRetryAndIncreaseBackoffDuration() {
-	time.Sleep(c.backoffInterval)
-	c.increaseReconnectBackoffInterval()
+// triggerReconnectHooks calls the reconnect hooks, returning the error of the first one failing with a channel
+// exception.
+func (c *connection) triggerReconnectHooks() error {
+	c.mutex.RLock()
+	hooks := c.reconnectHooks
+	c.mutex.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(); isChannelException(err) {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (c *connection) increaseReconnectBackoffInterval() {
-	c.backoffInterval *= 2
+// abort gives up on the connection after an unrecoverable failure, closing it without reconnecting.
+func (c *connection) abort(conn *amqp.Connection, err error) {
+	c.mutex.Lock()
+	c.isClosed = true
+	c.mutex.Unlock()
 
-	if c.backoffInterval > c.maxBackoffInterval {
-		c.backoffInterval = c.maxBackoffInterval
-	}
+	_ = c.giveUp(err)
+	_ = conn.Close()
 }
 
-func (c *connection) resetBackoffInterval() {
-	c.backoffInterval = c.initialBackoffInterval
+// isChannelException reports whether the error is a soft AMQP error, closing only the channel it happened on.
+func isChannelException(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Recover
 }
 
 func panicOnError(err error) {
@@ -121,3 +365,15 @@ func panicOnError(err error) {
 		panic(err)
 	}
 }
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,36 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+func TestIsChannelException(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "precondition failed", err: &amqp.Error{Code: amqp.PreconditionFailed, Recover: true}, want: true},
+		{
+			name: "wrapped access refused",
+			err:  fmt.Errorf("topology: declare exchange: %w", &amqp.Error{Code: amqp.AccessRefused, Recover: true}),
+			want: true,
+		},
+		{name: "channel closed", err: amqp.ErrClosed, want: false},
+		{name: "connection forced", err: &amqp.Error{Code: amqp.ConnectionForced}, want: false},
+		{name: "other error", err: errors.New("other"), want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isChannelException(test.err); got != test.want {
+				t.Errorf("isChannelException(%v) = %v, want %v", test.err, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package connection
+
+import (
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	/*
+		EndpointSelectionRoundRobin rotates over the endpoints.
+
+		This selection is the default and each (re)connection starts with the endpoint next to the last connected one.
+	*/
+	EndpointSelectionRoundRobin EndpointSelection = iota
+
+	/*
+		EndpointSelectionRandom picks the endpoints in a random order on each (re)connection.
+
+		Useful to spread many clients over the cluster nodes.
+	*/
+	EndpointSelectionRandom
+
+	/*
+		EndpointSelectionPrioritized always tries the endpoints in the configured order.
+
+		Each (re)connection starts with the first endpoint and only falls back to the next ones when it fails.
+	*/
+	EndpointSelectionPrioritized
+)
+
+// EndpointSelection defines the order the cluster endpoints are tried on each (re)connection.
+type EndpointSelection uint8
+
+type endpoints struct {
+	mutex     *sync.Mutex
+	uris      []string
+	selection EndpointSelection
+	next      int
+	random    *rand.Rand
+}
+
+func newEndpoints(uris []string, selection EndpointSelection) *endpoints {
+	return &endpoints{
+		mutex:     &sync.Mutex{},
+		uris:      uris,
+		selection: selection,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// order returns the indexes of the endpoints in the order they should be tried on the next connection attempt.
+func (e *endpoints) order() []int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	switch e.selection {
+	case EndpointSelectionRandom:
+		return e.random.Perm(len(e.uris))
+	case EndpointSelectionPrioritized:
+		return e.rotate(0)
+	default:
+		return e.rotate(e.next)
+	}
+}
+
+func (e *endpoints) rotate(start int) []int {
+	indexes := make([]int, len(e.uris))
+	for i := range indexes {
+		indexes[i] = (start + i) % len(e.uris)
+	}
+
+	return indexes
+}
+
+// connected records the endpoint a connection was established with, so the next round robin reconnection moves on.
+func (e *endpoints) connected(index int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.next = (index + 1) % len(e.uris)
+}
+
+func (e *endpoints) get(index int) string {
+	return e.uris[index]
+}
+
+// redactURI returns the URI without the password, so it can be safely logged.
+func redactURI(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Redacted()
+}
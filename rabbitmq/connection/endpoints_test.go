@@ -0,0 +1,70 @@
+package connection
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestEndpointsOrder(t *testing.T) {
+	uris := []string{"amqp://a", "amqp://b", "amqp://c"}
+	tests := []struct {
+		name      string
+		selection EndpointSelection
+		connected []int
+		want      []int
+	}{
+		{name: "round robin initial", selection: EndpointSelectionRoundRobin, want: []int{0, 1, 2}},
+		{
+			name:      "round robin after connection",
+			selection: EndpointSelectionRoundRobin,
+			connected: []int{0},
+			want:      []int{1, 2, 0},
+		},
+		{
+			name:      "round robin after connection to last",
+			selection: EndpointSelectionRoundRobin,
+			connected: []int{2},
+			want:      []int{0, 1, 2},
+		},
+		{
+			name:      "round robin after failover",
+			selection: EndpointSelectionRoundRobin,
+			connected: []int{0, 2, 1},
+			want:      []int{2, 0, 1},
+		},
+		{name: "prioritized initial", selection: EndpointSelectionPrioritized, want: []int{0, 1, 2}},
+		{
+			name:      "prioritized after connection",
+			selection: EndpointSelectionPrioritized,
+			connected: []int{1},
+			want:      []int{0, 1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := newEndpoints(uris, test.selection)
+			for _, index := range test.connected {
+				e.connected(index)
+			}
+
+			if got := e.order(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("order() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEndpointsRandomOrder(t *testing.T) {
+	e := newEndpoints([]string{"amqp://a", "amqp://b", "amqp://c", "amqp://d"}, EndpointSelectionRandom)
+	e.connected(1)
+
+	for i := 0; i < 100; i++ {
+		order := e.order()
+		sort.Ints(order)
+		if want := []int{0, 1, 2, 3}; !reflect.DeepEqual(order, want) {
+			t.Fatalf("order() = %v, want a permutation of %v", order, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// ErrBrokerBlocked is returned when publishing while the broker blocks the connection (e.g. on a memory or disk alarm).
+var ErrBrokerBlocked = errors.New("connection: blocked by the broker")
+
+/*
+	flowControl tracks the broker flow control state.
+
+	RabbitMQ blocks publishing connections (connection.blocked) when a memory or disk alarm fires, and may pause a
+	channel (channel.flow). While blocked, publishing hangs until the broker unblocks the connection.
+*/
+type flowControl struct {
+	mutex         *sync.Mutex
+	isBlocked     bool
+	blockedReason string
+	isFlowPaused  bool
+	released      chan struct{}
+}
+
+func newFlowControl() *flowControl {
+	released := make(chan struct{})
+	close(released)
+	return &flowControl{mutex: &sync.Mutex{}, released: released}
+}
+
+func (f *flowControl) watchBlocked(conn *amqp.Connection) {
+	for blocking := range conn.NotifyBlocked(make(chan amqp.Blocking, 1)) {
+		f.update(func() {
+			f.isBlocked = blocking.Active
+			f.blockedReason = blocking.Reason
+		})
+	}
+
+	f.update(func() {
+		f.isBlocked = false
+		f.blockedReason = ""
+	})
+}
+
+func (f *flowControl) watchFlow(channel *amqp.Channel) {
+	for isActive := range channel.NotifyFlow(make(chan bool, 1)) {
+		f.update(func() {
+			f.isFlowPaused = !isActive
+		})
+	}
+
+	f.update(func() {
+		f.isFlowPaused = false
+	})
+}
+
+func (f *flowControl) update(change func()) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	wasBlocked := f.isBlockedLocked()
+	change()
+	isBlocked := f.isBlockedLocked()
+
+	switch {
+	case !wasBlocked && isBlocked:
+		f.released = make(chan struct{})
+	case wasBlocked && !isBlocked:
+		close(f.released)
+	}
+}
+
+func (f *flowControl) isBlockedLocked() bool {
+	return f.isBlocked || f.isFlowPaused
+}
+
+func (f *flowControl) IsBlocked() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.isBlockedLocked()
+}
+
+func (f *flowControl) BlockedReason() string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.isFlowPaused && !f.isBlocked {
+		return "channel flow paused"
+	}
+
+	return f.blockedReason
+}
+
+func (f *flowControl) WaitUnblocked(ctx context.Context) error {
+	f.mutex.Lock()
+	released := f.released
+	f.mutex.Unlock()
+
+	select {
+	case <-released:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,10 +1,18 @@
 package connection
 
-import "time"
+import (
+	"time"
+
+	"github.com/streadway/amqp"
+)
 
 const (
 	defaultInitialBackoffInterval = 1 * time.Second
 	defaultMaxBackoffInterval     = 1 * time.Minute
+	defaultHeartbeat              = 10 * time.Second
+	defaultDialTimeout            = 30 * time.Second
+	defaultLocale                 = "en_US"
+	defaultProduct                = "github.com/maykonlf/pubsub"
 )
 
 // Options contains the RabbitMQ connection and reconnection params
@@ -14,6 +22,17 @@ type Options struct {
 	*/
 	URI string
 
+	/*
+		URIs are additional cluster endpoints, with the same format as URI.
+
+		When a connection attempt fails the next endpoint is tried, and the order the endpoints are tried on each
+		(re)connection is defined by EndpointSelection.
+	*/
+	URIs []string
+
+	// EndpointSelection defines the order the endpoints are tried. See EndpointSelection for details.
+	EndpointSelection EndpointSelection
+
 	/*
 		InitialBackoffInterval is the initial delay between reconnections.
 
@@ -33,6 +52,51 @@ type Options struct {
 		Once the client successful reconnects the backoff interval is set back to the initial value.
 	*/
 	MaxBackoffInterval time.Duration
+
+	/*
+		BackoffStrategy computes the delay between connection attempts, for both the initial connection and the
+		reconnections. See BackoffStrategy for details.
+
+		Defaults to an exponential backoff (without jitter) between InitialBackoffInterval and MaxBackoffInterval which
+		never gives up.
+	*/
+	BackoffStrategy BackoffStrategy
+
+	/*
+		TLS contains the TLS settings (CA bundle, client certificate, server name and min version).
+
+		TLS is only used by "amqps://" URIs.
+	*/
+	TLS *TLSOptions
+
+	/*
+		ExternalAuth enables the SASL EXTERNAL authentication mechanism, where the broker authenticates the client
+		by its TLS client certificate instead of the URI username and password.
+
+		Requires the rabbitmq_auth_mechanism_ssl plugin and a TLS client certificate.
+	*/
+	ExternalAuth bool
+
+	// Heartbeat is the connection heartbeat interval (default: 10s). Values lower than 1s use the server interval.
+	Heartbeat time.Duration
+
+	// FrameSize is the max frame size in bytes (default: 0, unlimited).
+	FrameSize int
+
+	// ChannelMax is the max number of channels (default: 0, server limit).
+	ChannelMax int
+
+	// Locale is the connection locale (default: "en_US").
+	Locale string
+
+	// DialTimeout is the TCP connection, TLS and AMQP handshake timeout (default: 30s).
+	DialTimeout time.Duration
+
+	// ConnectionName is the connection name shown by the RabbitMQ management UI.
+	ConnectionName string
+
+	// ClientProperties are additional properties advertised to the server.
+	ClientProperties map[string]interface{}
 }
 
 func (c *Options) getInitialBackoffInterval() time.Duration {
@@ -50,3 +114,87 @@ func (c *Options) getMaxBackoffInterval() time.Duration {
 
 	return c.MaxBackoffInterval
 }
+
+func (c *Options) getBackoffStrategy() BackoffStrategy {
+	if c.BackoffStrategy == nil {
+		return NewExponentialBackoff(c.getInitialBackoffInterval(), c.getMaxBackoffInterval())
+	}
+
+	return c.BackoffStrategy
+}
+
+func (c *Options) getURIs() []string {
+	if c.URI == "" {
+		return c.URIs
+	}
+
+	return append([]string{c.URI}, c.URIs...)
+}
+
+func (c *Options) getHeartbeat() time.Duration {
+	if c.Heartbeat == 0 {
+		return defaultHeartbeat
+	}
+
+	return c.Heartbeat
+}
+
+func (c *Options) getDialTimeout() time.Duration {
+	if c.DialTimeout == 0 {
+		return defaultDialTimeout
+	}
+
+	return c.DialTimeout
+}
+
+func (c *Options) getLocale() string {
+	if c.Locale == "" {
+		return defaultLocale
+	}
+
+	return c.Locale
+}
+
+func (c *Options) getClientProperties() amqp.Table {
+	properties := amqp.Table{"product": defaultProduct}
+	for key, value := range c.ClientProperties {
+		properties[key] = value
+	}
+
+	if c.ConnectionName != "" {
+		properties["connection_name"] = c.ConnectionName
+	}
+
+	return properties
+}
+
+func (c *Options) getSASL() []amqp.Authentication {
+	if c.ExternalAuth {
+		return []amqp.Authentication{externalAuth{}}
+	}
+
+	return nil
+}
+
+func (c *Options) getConfig() (amqp.Config, error) {
+	config := amqp.Config{
+		SASL:       c.getSASL(),
+		ChannelMax: c.ChannelMax,
+		FrameSize:  c.FrameSize,
+		Heartbeat:  c.getHeartbeat(),
+		Properties: c.getClientProperties(),
+		Locale:     c.getLocale(),
+		Dial:       amqp.DefaultDial(c.getDialTimeout()),
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.getTLSConfig()
+		if err != nil {
+			return amqp.Config{}, err
+		}
+
+		config.TLSClientConfig = tlsConfig
+	}
+
+	return config, nil
+}
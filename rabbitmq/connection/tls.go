@@ -0,0 +1,106 @@
+package connection
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSOptions contains the TLS settings used to connect to "amqps://" URIs.
+type TLSOptions struct {
+	/*
+		CAFile is the path of a PEM encoded CA bundle used to verify the server certificate.
+
+		When empty the host root CA set is used.
+	*/
+	CAFile string
+
+	/*
+		CertFile and KeyFile are the paths of the PEM encoded client certificate and private key used for mutual TLS
+		(and required by EXTERNAL authentication).
+
+		Files are read on every (re)connection, so a rotated certificate is picked up on the next reconnection.
+	*/
+	CertFile string
+
+	// KeyFile is the path of the PEM encoded client private key. See CertFile for details.
+	KeyFile string
+
+	// ServerName is the server name used to verify the server certificate (defaults to the URI host).
+	ServerName string
+
+	// MinVersion is the minimum accepted TLS version (e.g. tls.VersionTLS12). Defaults to TLS 1.2.
+	MinVersion uint16
+
+	// Config is an optional base TLS configuration, cloned and completed by the other options.
+	Config *tls.Config
+}
+
+func (o *TLSOptions) getTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{}
+	if o.Config != nil {
+		config = o.Config.Clone()
+	}
+
+	config.MinVersion = o.getMinVersion()
+	if o.ServerName != "" {
+		config.ServerName = o.ServerName
+	}
+
+	if err := o.loadCA(config); err != nil {
+		return nil, err
+	}
+
+	if err := o.loadClientCertificate(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (o *TLSOptions) getMinVersion() uint16 {
+	if o.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+
+	return o.MinVersion
+}
+
+func (o *TLSOptions) loadCA(config *tls.Config) error {
+	if o.CAFile == "" {
+		return nil
+	}
+
+	pem, err := ioutil.ReadFile(o.CAFile)
+	if err != nil {
+		return fmt.Errorf("connection: read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("connection: no certificate found in CA file %q", o.CAFile)
+	}
+
+	config.RootCAs = pool
+	return nil
+}
+
+func (o *TLSOptions) loadClientCertificate(config *tls.Config) error {
+	if o.CertFile == "" && o.KeyFile == "" {
+		return nil
+	}
+
+	if o.CertFile == "" || o.KeyFile == "" {
+		return errors.New("connection: both TLS CertFile and KeyFile must be set")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+	if err != nil {
+		return fmt.Errorf("connection: load client certificate: %w", err)
+	}
+
+	config.Certificates = []tls.Certificate{certificate}
+	return nil
+}
@@ -0,0 +1,93 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// ErrInvalidHeader is returned when a message header value can't be encoded in an AMQP table.
+var ErrInvalidHeader = errors.New("rabbitmq: invalid message header")
+
+// HeadersTable converts the message headers to an AMQP table, converting nested maps to tables, and returns an
+// ErrInvalidHeader wrapped error when a value type can't be encoded (e.g. unsigned integers other than uint8 or
+// structs).
+func HeadersTable(headers map[string]interface{}) (amqp.Table, error) {
+	table := toTable(headers)
+	if err := table.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	return table, nil
+}
+
+func toTable(headers map[string]interface{}) amqp.Table {
+	if headers == nil {
+		return nil
+	}
+
+	table := make(amqp.Table, len(headers))
+	for key, value := range headers {
+		table[key] = toTableValue(value)
+	}
+
+	return table
+}
+
+func toTableValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return toTable(v)
+	case amqp.Table:
+		return toTable(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = toTableValue(v[i])
+		}
+
+		return values
+	case []byte:
+		return append([]byte(nil), v...)
+	default:
+		return value
+	}
+}
+
+func copyHeaders(headers map[string]interface{}) map[string]interface{} {
+	if headers == nil {
+		return map[string]interface{}{}
+	}
+
+	return toTable(headers)
+}
+
+func headerInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func headerTime(value interface{}) (time.Time, bool) {
+	timestamp, ok := value.(time.Time)
+	return timestamp, ok
+}
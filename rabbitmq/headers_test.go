@@ -0,0 +1,97 @@
+package rabbitmq_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maykonlf/pubsub/rabbitmq"
+	"github.com/streadway/amqp"
+)
+
+func TestHeadersTable(t *testing.T) {
+	timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]interface{}
+		want    amqp.Table
+	}{
+		{name: "nil", headers: nil, want: nil},
+		{name: "empty", headers: map[string]interface{}{}, want: amqp.Table{}},
+		{
+			name: "scalars",
+			headers: map[string]interface{}{
+				"string": "value", "bool": true, "int": 1, "int64": int64(2), "float": 1.5, "byte": uint8(3),
+				"bytes": []byte("data"), "time": timestamp, "nil": nil,
+			},
+			want: amqp.Table{
+				"string": "value", "bool": true, "int": 1, "int64": int64(2), "float": 1.5, "byte": uint8(3),
+				"bytes": []byte("data"), "time": timestamp, "nil": nil,
+			},
+		},
+		{
+			name:    "nested map",
+			headers: map[string]interface{}{"nested": map[string]interface{}{"key": "value"}},
+			want:    amqp.Table{"nested": amqp.Table{"key": "value"}},
+		},
+		{
+			name:    "nested table",
+			headers: map[string]interface{}{"nested": amqp.Table{"key": map[string]interface{}{"deep": int32(1)}}},
+			want:    amqp.Table{"nested": amqp.Table{"key": amqp.Table{"deep": int32(1)}}},
+		},
+		{
+			name:    "array of maps",
+			headers: map[string]interface{}{"array": []interface{}{"value", map[string]interface{}{"key": 1}}},
+			want:    amqp.Table{"array": []interface{}{"value", amqp.Table{"key": 1}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, err := rabbitmq.HeadersTable(test.headers)
+			if err != nil {
+				t.Fatalf("HeadersTable() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(table, test.want) {
+				t.Errorf("HeadersTable() = %#v, want %#v", table, test.want)
+			}
+		})
+	}
+}
+
+func TestHeadersTableInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]interface{}
+	}{
+		{name: "uint", headers: map[string]interface{}{"key": uint(1)}},
+		{name: "uint64", headers: map[string]interface{}{"key": uint64(1)}},
+		{name: "struct", headers: map[string]interface{}{"key": struct{}{}}},
+		{name: "string slice", headers: map[string]interface{}{"key": []string{"value"}}},
+		{name: "nested", headers: map[string]interface{}{"key": map[string]interface{}{"deep": uint16(1)}}},
+		{name: "in array", headers: map[string]interface{}{"key": []interface{}{uint32(1)}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := rabbitmq.HeadersTable(test.headers); !errors.Is(err, rabbitmq.ErrInvalidHeader) {
+				t.Errorf("HeadersTable() error = %v, want %v", err, rabbitmq.ErrInvalidHeader)
+			}
+		})
+	}
+}
+
+func TestHeadersTableCopiesBytes(t *testing.T) {
+	data := []byte("data")
+	table, err := rabbitmq.HeadersTable(map[string]interface{}{"bytes": data})
+	if err != nil {
+		t.Fatalf("HeadersTable() error = %v", err)
+	}
+
+	data[0] = 'D'
+	if got := table["bytes"].([]byte); string(got) != "data" {
+		t.Errorf("HeadersTable() bytes = %q, want a copy of the original value", got)
+	}
+}
@@ -16,10 +16,13 @@ type Message struct {
 	contentType     string
 	contentEncoding string
 	body            []byte
-	deliveryMode    uint8
+	deliveryMode    pubsub.DeliveryMode
 	priority        uint8
+	priorityLevel   pubsub.PriorityLevel
 	replyTo         string
 	expiration      time.Duration
+	delay           time.Duration
+	deliverAt       time.Time
 	messageType     string
 	userID          string
 	appID           string
@@ -29,18 +32,18 @@ type Message struct {
 
 // NewMessage creates a new RabbitMQ message with a unique new ID.
 func NewMessage() pubsub.Message {
-	return &Message{id: uuid.New()}
+	return &Message{id: uuid.New(), headers: map[string]interface{}{}}
 }
 
 func NewMessageFromDelivery(delivery amqp.Delivery) pubsub.Message {
 	return &Message{
-		id:              uuid.MustParse(delivery.MessageId),
-		correlationID:   uuid.MustParse(delivery.CorrelationId),
-		headers:         delivery.Headers,
+		id:              parseUUID(delivery.MessageId),
+		correlationID:   parseUUID(delivery.CorrelationId),
+		headers:         copyHeaders(delivery.Headers),
 		contentType:     delivery.ContentType,
 		contentEncoding: delivery.ContentEncoding,
 		body:            delivery.Body,
-		deliveryMode:    delivery.DeliveryMode,
+		deliveryMode:    pubsub.DeliveryMode(delivery.DeliveryMode),
 		priority:        delivery.Priority,
 		replyTo:         delivery.ReplyTo,
 		expiration:      parseDurationStringToTimeDuration(delivery.Expiration),
@@ -52,25 +55,53 @@ func NewMessageFromDelivery(delivery amqp.Delivery) pubsub.Message {
 	}
 }
 
+// parseUUID parses the delivery message and correlation IDs, returning uuid.Nil when unset or not an UUID (e.g. set
+// by publishers using other libraries).
+func parseUUID(s string) uuid.UUID {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	return id
+}
+
 func parseDurationStringToTimeDuration(s string) time.Duration {
 	milliseconds, _ := strconv.ParseInt(s, 10, 64)
 	return time.Duration(milliseconds) * time.Millisecond
 }
 
+func (m *Message) Clone() pubsub.Message {
+	clone := *m
+	clone.headers = copyHeaders(m.headers)
+	clone.body = append([]byte(nil), m.body...)
+	clone.delivery = amqp.Delivery{}
+	return &clone
+}
+
 func (m *Message) ID() uuid.UUID {
 	return m.id
 }
 
+func (m *Message) SetID(id uuid.UUID) pubsub.Message {
+	m.id = id
+	return m
+}
+
 func (m *Message) SetCorrelationID(id uuid.UUID) pubsub.Message {
 	m.correlationID = id
 	return m
 }
 
 func (m *Message) CorrelationID() uuid.UUID {
-	return m.id
+	return m.correlationID
 }
 
 func (m *Message) SetHeader(key string, value interface{}) pubsub.Message {
+	if m.headers == nil {
+		m.headers = map[string]interface{}{}
+	}
+
 	m.headers[key] = value
 	return m
 }
@@ -79,12 +110,25 @@ func (m *Message) GetHeader(key string) interface{} {
 	return m.headers[key]
 }
 
+func (m *Message) HeaderString(key string) (string, bool) {
+	value, ok := m.headers[key].(string)
+	return value, ok
+}
+
+func (m *Message) HeaderInt64(key string) (int64, bool) {
+	return headerInt64(m.headers[key])
+}
+
+func (m *Message) HeaderTime(key string) (time.Time, bool) {
+	return headerTime(m.headers[key])
+}
+
 func (m *Message) Headers() map[string]interface{} {
-	return m.headers
+	return copyHeaders(m.headers)
 }
 
 func (m *Message) SetHeaders(headers map[string]interface{}) pubsub.Message {
-	m.headers = headers
+	m.headers = copyHeaders(headers)
 	return m
 }
 
@@ -116,19 +160,42 @@ func (m *Message) Body() []byte {
 }
 
 func (m *Message) SetDeliveryModePersistent() pubsub.Message {
-	m.deliveryMode = 2
+	return m.SetDeliveryMode(pubsub.Persistent)
+}
+
+func (m *Message) SetDeliveryMode(mode pubsub.DeliveryMode) pubsub.Message {
+	m.deliveryMode = mode
 	return m
 }
 
-func (m *Message) DeliveryMode() uint8 {
+func (m *Message) DeliveryMode() pubsub.DeliveryMode {
+	if !m.HasDeliveryMode() {
+		return pubsub.Transient
+	}
+
 	return m.deliveryMode
 }
 
+func (m *Message) HasDeliveryMode() bool {
+	return m.deliveryMode != 0
+}
+
 func (m *Message) SetPriority(priority uint8) pubsub.Message {
 	m.priority = priority
+	m.priorityLevel = 0
+	return m
+}
+
+func (m *Message) SetPriorityLevel(level pubsub.PriorityLevel) pubsub.Message {
+	m.priorityLevel = level
+	m.priority = 0
 	return m
 }
 
+func (m *Message) PriorityLevel() pubsub.PriorityLevel {
+	return m.priorityLevel
+}
+
 func (m *Message) Priority() uint8 {
 	return m.priority
 }
@@ -151,6 +218,34 @@ func (m *Message) Expiration() time.Duration {
 	return m.expiration
 }
 
+func (m *Message) SetDelay(delay time.Duration) pubsub.Message {
+	m.delay = delay
+	m.deliverAt = time.Time{}
+	return m
+}
+
+func (m *Message) Delay() time.Duration {
+	if m.deliverAt.IsZero() {
+		return m.delay
+	}
+
+	if delay := time.Until(m.deliverAt); delay > 0 {
+		return delay
+	}
+
+	return 0
+}
+
+func (m *Message) SetDeliverAt(deliverAt time.Time) pubsub.Message {
+	m.deliverAt = deliverAt
+	m.delay = 0
+	return m
+}
+
+func (m *Message) DeliverAt() time.Time {
+	return m.deliverAt
+}
+
 func (m *Message) SetType(v string) pubsub.Message {
 	m.messageType = v
 	return m
@@ -187,6 +282,10 @@ func (m *Message) Timestamp() time.Time {
 	return m.timestamp
 }
 
+func (m *Message) RoutingKey() string {
+	return m.delivery.RoutingKey
+}
+
 func (m *Message) Ack() error {
 	return m.delivery.Ack(false)
 }
@@ -0,0 +1,35 @@
+package rabbitmq_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/maykonlf/pubsub/rabbitmq"
+	"github.com/streadway/amqp"
+)
+
+func TestNewMessageFromDeliveryIDs(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name  string
+		value string
+		want  uuid.UUID
+	}{
+		{name: "uuid", value: id.String(), want: id},
+		{name: "empty", value: "", want: uuid.Nil},
+		{name: "not an uuid", value: "order-42", want: uuid.Nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := rabbitmq.NewMessageFromDelivery(amqp.Delivery{MessageId: test.value, CorrelationId: test.value})
+			if message.ID() != test.want {
+				t.Errorf("ID() = %s, want %s", message.ID(), test.want)
+			}
+
+			if message.CorrelationID() != test.want {
+				t.Errorf("CorrelationID() = %s, want %s", message.CorrelationID(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package rabbitmq_test
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+)
+
+// faultProxy forwards TCP connections to the broker and can kill them, simulating network failures.
+type faultProxy struct {
+	listener net.Listener
+	target   string
+	mutex    *sync.Mutex
+	conns    []net.Conn
+}
+
+func newFaultProxy(t *testing.T, target string) *faultProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := &faultProxy{listener: listener, target: target, mutex: &sync.Mutex{}}
+	t.Cleanup(proxy.close)
+	go proxy.accept()
+	return proxy
+}
+
+func (p *faultProxy) addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *faultProxy) accept() {
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		server, err := net.Dial("tcp", p.target)
+		if err != nil {
+			_ = client.Close()
+			continue
+		}
+
+		p.mutex.Lock()
+		p.conns = append(p.conns, client, server)
+		p.mutex.Unlock()
+
+		go pipe(client, server)
+		go pipe(server, client)
+	}
+}
+
+// killConnections abruptly closes the proxied connections, without AMQP close handshake.
+func (p *faultProxy) killConnections() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, conn := range p.conns {
+		_ = conn.Close()
+	}
+
+	p.conns = nil
+}
+
+func (p *faultProxy) close() {
+	_ = p.listener.Close()
+	p.killConnections()
+}
+
+func pipe(dst, src net.Conn) {
+	_, _ = io.Copy(dst, src)
+	_ = dst.Close()
+	_ = src.Close()
+}
@@ -0,0 +1,135 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/streadway/amqp"
+)
+
+const (
+	/*
+		OverflowBlock blocks the publish call until the buffer has room for the publishing.
+
+		This policy is the default and never loses publishings, but publishers may hang while the broker is down.
+	*/
+	OverflowBlock OverflowPolicy = iota
+
+	/*
+		OverflowDropOldest drops the oldest buffered publishing to make room for the new one.
+
+		Useful when only the most recent messages are relevant (e.g. metrics or status updates).
+	*/
+	OverflowDropOldest
+
+	/*
+		OverflowError rejects the publishing returning ErrBufferFull.
+	*/
+	OverflowError
+)
+
+// ErrBufferFull is returned by Publish when the publish buffer is full and the overflow policy is OverflowError.
+var ErrBufferFull = errors.New("publisher: publish buffer is full")
+
+// OverflowPolicy defines what happens when publishing while the publish buffer is full.
+type OverflowPolicy uint8
+
+// buffer holds the publishings made while the broker is unreachable, applying the capacity and overflow policy over
+// the underlying spool.
+type buffer struct {
+	mutex    *sync.Mutex
+	popped   chan struct{}
+	spool    Spool
+	capacity int
+	policy   OverflowPolicy
+}
+
+func newBuffer(spool Spool, capacity int, policy OverflowPolicy) *buffer {
+	return &buffer{
+		mutex:    &sync.Mutex{},
+		popped:   make(chan struct{}),
+		spool:    spool,
+		capacity: capacity,
+		policy:   policy,
+	}
+}
+
+func (b *buffer) push(ctx context.Context, publishing *Publishing) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for b.isFull() {
+		switch b.policy {
+		case OverflowError:
+			return ErrBufferFull
+		case OverflowDropOldest:
+			if err := b.spool.Pop(); err != nil {
+				return err
+			}
+		default:
+			if err := b.waitPop(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return b.spool.Push(publishing)
+}
+
+// waitPop releases the buffer lock until a buffered publishing is popped or ctx is done.
+func (b *buffer) waitPop(ctx context.Context) error {
+	popped := b.popped
+	b.mutex.Unlock()
+	defer b.mutex.Lock()
+
+	select {
+	case <-popped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *buffer) isFull() bool {
+	return b.capacity > 0 && b.spool.Len() >= b.capacity
+}
+
+// flush publishes the buffered publishings in order, stopping on the first retryable failure so the remaining ones
+// are kept for the next flush. Publishings which can't be read from the spool or fail permanently (e.g. rejected by
+// the broker) are dropped, so they don't hold back the ones behind them.
+func (b *buffer) flush(publish func(publishing *Publishing) error) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for b.spool.Len() > 0 {
+		publishing, err := b.spool.Peek()
+		if err == nil {
+			err = publish(publishing)
+		}
+
+		if isRetryable(err) {
+			return err
+		}
+
+		if err := b.spool.Pop(); err != nil {
+			return err
+		}
+
+		close(b.popped)
+		b.popped = make(chan struct{})
+	}
+
+	return nil
+}
+
+// isRetryable reports whether a failed publishing may succeed once the connection recovers.
+func isRetryable(err error) bool {
+	return errors.Is(err, amqp.ErrClosed) ||
+		errors.Is(err, connection.ErrBrokerBlocked) ||
+		errors.Is(err, connection.ErrGaveUp) ||
+		errors.Is(err, connection.ErrClosed) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,119 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestBufferPushOverflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		wantErr error
+		want    []string
+	}{
+		{name: "block", policy: OverflowBlock, wantErr: context.DeadlineExceeded, want: []string{"1", "2"}},
+		{name: "drop oldest", policy: OverflowDropOldest, want: []string{"2", "3"}},
+		{name: "error", policy: OverflowError, wantErr: ErrBufferFull, want: []string{"1", "2"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := newBuffer(NewMemorySpool(), 2, test.policy)
+			for _, routingKey := range []string{"1", "2"} {
+				if err := b.push(context.Background(), &Publishing{RoutingKey: routingKey}); err != nil {
+					t.Fatalf("push() error = %v", err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			if err := b.push(ctx, &Publishing{RoutingKey: "3"}); !errors.Is(err, test.wantErr) {
+				t.Errorf("push() error = %v, want %v", err, test.wantErr)
+			}
+
+			if got := flushRoutingKeys(t, b); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("buffered publishings = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBufferPushBlockUntilFlush(t *testing.T) {
+	b := newBuffer(NewMemorySpool(), 1, OverflowBlock)
+	if err := b.push(context.Background(), &Publishing{RoutingKey: "1"}); err != nil {
+		t.Fatalf("push() error = %v", err)
+	}
+
+	pushed := make(chan error)
+	go func() {
+		pushed <- b.push(context.Background(), &Publishing{RoutingKey: "2"})
+	}()
+
+	if got := flushRoutingKeys(t, b); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("buffered publishings = %v, want [1]", got)
+	}
+
+	if err := <-pushed; err != nil {
+		t.Errorf("push() error = %v", err)
+	}
+
+	if got := flushRoutingKeys(t, b); !reflect.DeepEqual(got, []string{"2"}) {
+		t.Errorf("buffered publishings = %v, want [2]", got)
+	}
+}
+
+func TestBufferFlush(t *testing.T) {
+	b := newBuffer(NewMemorySpool(), 0, OverflowBlock)
+	for _, routingKey := range []string{"1", "rejected", "2", "closed", "3"} {
+		if err := b.push(context.Background(), &Publishing{RoutingKey: routingKey}); err != nil {
+			t.Fatalf("push() error = %v", err)
+		}
+	}
+
+	var published []string
+	err := b.flush(func(publishing *Publishing) error {
+		switch publishing.RoutingKey {
+		case "rejected":
+			return ErrNacked
+		case "closed":
+			return amqp.ErrClosed
+		}
+
+		published = append(published, publishing.RoutingKey)
+		return nil
+	})
+	if !errors.Is(err, amqp.ErrClosed) {
+		t.Errorf("flush() error = %v, want %v", err, amqp.ErrClosed)
+	}
+
+	if !reflect.DeepEqual(published, []string{"1", "2"}) {
+		t.Errorf("published = %v, want [1 2]", published)
+	}
+
+	if got := flushRoutingKeys(t, b); !reflect.DeepEqual(got, []string{"closed", "3"}) {
+		t.Errorf("buffered publishings = %v, want [closed 3]", got)
+	}
+}
+
+// flushRoutingKeys flushes the buffer, returning the routing keys of the buffered publishings in order.
+func flushRoutingKeys(t *testing.T, b *buffer) []string {
+	t.Helper()
+
+	var routingKeys []string
+	err := b.flush(func(publishing *Publishing) error {
+		routingKeys = append(routingKeys, publishing.RoutingKey)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	return routingKeys
+}
@@ -0,0 +1,28 @@
+package publisher
+
+import (
+	"fmt"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/compression"
+)
+
+// compress returns a clone of the message with the body compressed when it has at least the compression threshold
+// and no content encoding yet, or the message itself otherwise.
+func (p *publisher) compress(m pubsub.Message) (pubsub.Message, error) {
+	if p.compression == nil || m.ContentEncoding() != "" || len(m.Body()) < p.compressionThreshold {
+		return m, nil
+	}
+
+	compressed, err := p.compression.Compress(m.Body())
+	if err != nil {
+		return nil, fmt.Errorf("publisher: compress %s: %w", p.compression.Encoding(), err)
+	}
+
+	return m.Clone().SetBody(compressed).SetContentEncoding(p.compression.Encoding()), nil
+}
+
+func (p *publisher) SetCompression(codec compression.Codec, threshold int) {
+	p.compression = codec
+	p.compressionThreshold = threshold
+}
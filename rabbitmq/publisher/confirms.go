@@ -0,0 +1,182 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	// ErrNacked is returned when the broker negatively acknowledges a confirmed publishing.
+	ErrNacked = errors.New("publisher: publishing nacked by the broker")
+
+	// ErrUnroutable is returned when the broker returns a mandatory publishing which could not be routed to any queue.
+	ErrUnroutable = errors.New("publisher: publishing returned as unroutable")
+)
+
+/*
+	confirmTracker puts the publisher channel in confirm mode when the first confirmed publishing is made, and
+	dispatches the broker confirmations (and mandatory returns) to the publishings waiting for them.
+
+	Once in confirm mode every publishing on the channel gets a delivery tag, so the tracker must reserve a tag for
+	all publishings, even the ones which do not wait for a confirmation. A re-opened channel starts a new sequence in
+	non-confirm mode, and the publishings still waiting on the previous channel fail with amqp.ErrClosed.
+
+	Returns carry no delivery tag, but the broker sends the return of a publishing right before its confirmation. The
+	notification channels are unbuffered so the listener receives both in the broker order, and a return is matched
+	with the confirmation following it.
+*/
+type confirmTracker struct {
+	mutex     *sync.Mutex
+	channel   *amqp.Channel
+	isEnabled bool
+	lastTag   uint64
+	waiters   map[uint64]*confirmation
+}
+
+// confirmation is the pending broker confirmation of a publishing.
+type confirmation struct {
+	result chan error
+}
+
+func newConfirmTracker() *confirmTracker {
+	return &confirmTracker{mutex: &sync.Mutex{}}
+}
+
+// prepare tracks the given channel, enabling its confirm mode when required.
+func (t *confirmTracker) prepare(channel *amqp.Channel, requireConfirm bool) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.channel != channel {
+		t.failWaiters()
+		t.channel = channel
+		t.isEnabled = false
+		t.lastTag = 0
+		t.waiters = map[uint64]*confirmation{}
+	}
+
+	if t.isEnabled || !requireConfirm {
+		return nil
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		return err
+	}
+
+	t.isEnabled = true
+	go t.listen(channel, channel.NotifyPublish(make(chan amqp.Confirmation)),
+		channel.NotifyReturn(make(chan amqp.Return)))
+	return nil
+}
+
+// reserve reserves the delivery tag of the next publishing, returning its pending confirmation when requested.
+func (t *confirmTracker) reserve(requireConfirm bool) *confirmation {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.isEnabled {
+		return nil
+	}
+
+	t.lastTag++
+	if !requireConfirm {
+		return nil
+	}
+
+	pending := &confirmation{result: make(chan error, 1)}
+	t.waiters[t.lastTag] = pending
+	return pending
+}
+
+// release releases the last reserved delivery tag when the publishing failed.
+func (t *confirmTracker) release() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.isEnabled {
+		return
+	}
+
+	delete(t.waiters, t.lastTag)
+	t.lastTag--
+}
+
+func (t *confirmTracker) listen(channel *amqp.Channel, confirms <-chan amqp.Confirmation, returns <-chan amqp.Return) {
+	isReturned := false
+	for {
+		select {
+		case _, isOpen := <-returns:
+			if !isOpen {
+				returns = nil
+				continue
+			}
+
+			isReturned = true
+
+		case c, isOpen := <-confirms:
+			if !isOpen {
+				t.failPending(channel)
+				return
+			}
+
+			t.resolve(channel, c, isReturned)
+			isReturned = false
+		}
+	}
+}
+
+func (t *confirmTracker) resolve(channel *amqp.Channel, c amqp.Confirmation, isReturned bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.channel != channel {
+		return
+	}
+
+	pending, found := t.waiters[c.DeliveryTag]
+	if !found {
+		return
+	}
+
+	delete(t.waiters, c.DeliveryTag)
+	switch {
+	case !c.Ack:
+		pending.result <- ErrNacked
+	case isReturned:
+		pending.result <- ErrUnroutable
+	default:
+		pending.result <- nil
+	}
+}
+
+func (t *confirmTracker) failPending(channel *amqp.Channel) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.channel != channel {
+		return
+	}
+
+	t.failWaiters()
+}
+
+// failWaiters fails the publishings waiting for a confirmation on the tracked channel, which must be locked.
+func (t *confirmTracker) failWaiters() {
+	for tag, pending := range t.waiters {
+		pending.result <- amqp.ErrClosed
+		delete(t.waiters, tag)
+	}
+}
+
+// wait waits for the broker confirmation or until ctx is done.
+func (c *confirmation) wait(ctx context.Context) error {
+	select {
+	case err := <-c.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,38 @@
+package publisher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+func TestConfirmTrackerChannelChange(t *testing.T) {
+	tracker := newConfirmTracker()
+	tracker.channel = &amqp.Channel{}
+	tracker.isEnabled = true
+	tracker.waiters = map[uint64]*confirmation{}
+
+	pending := tracker.reserve(true)
+	if pending == nil {
+		t.Fatal("reserve() = nil, want a pending confirmation")
+	}
+
+	if err := tracker.prepare(&amqp.Channel{}, false); err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+
+	select {
+	case err := <-pending.result:
+		if !errors.Is(err, amqp.ErrClosed) {
+			t.Errorf("confirmation error = %v, want %v", err, amqp.ErrClosed)
+		}
+	default:
+		t.Error("prepare() did not fail the confirmation pending on the previous channel")
+	}
+
+	if tracker.isEnabled || tracker.lastTag != 0 || len(tracker.waiters) != 0 {
+		t.Errorf("prepare() kept the previous channel state: isEnabled = %v, lastTag = %d, waiters = %d",
+			tracker.isEnabled, tracker.lastTag, len(tracker.waiters))
+	}
+}
@@ -0,0 +1,240 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/maykonlf/pubsub/rabbitmq/topology"
+	"github.com/streadway/amqp"
+)
+
+const (
+	delayedExchangeType     = "x-delayed-message"
+	delayedTypeArg          = "x-delayed-type"
+	delayHeader             = "x-delay"
+	delayedExchangePrefix   = "pubsub.delayed."
+	delayProbeExchange      = "pubsub.delayed.probe"
+	parkingQueuePrefix      = "pubsub.delay."
+	parkingQueueGranularity = time.Second
+	parkingQueueExpiration  = time.Minute
+
+	// parkingQueueRedeclareInterval is shorter than the parking queues expiration, since publishing to a queue
+	// doesn't renew its expiration lease while declaring it does.
+	parkingQueueRedeclareInterval = parkingQueueExpiration / 2
+)
+
+const (
+	/*
+		DelayBackendAuto uses the delayed message exchange plugin when the broker has it enabled, otherwise the TTL
+		parking queues.
+
+		This backend is the default and the plugin availability is probed on a short-lived connection, once it gives a
+		conclusive answer: while the broker can't be reached the delayed messages are parked in TTL queues and the
+		next delayed publishing probes again.
+	*/
+	DelayBackendAuto DelayBackend = iota
+
+	/*
+		DelayBackendPlugin uses the rabbitmq_delayed_message_exchange plugin.
+
+		Delayed messages are published with an "x-delay" header to a "pubsub.delayed.<exchange>" exchange of type
+		"x-delayed-message", which is bound to the target exchange and forwards the messages once the delay elapses.
+		The default exchange can't be bound, so messages delayed on it are parked in TTL queues instead.
+	*/
+	DelayBackendPlugin
+
+	/*
+		DelayBackendQueues parks the delayed messages in TTL queues dead-lettered to the target exchange.
+
+		A "pubsub.delay.<exchange>.<routing key>.<delay>" queue is declared per exchange, routing key and delay
+		(rounded up to seconds), and deleted by the broker when unused. The message expiration is not kept, since it
+		would expire the message while parked.
+
+		Parked publishings are mandatory and confirmed, so they fail with ErrUnroutable instead of being silently
+		dropped if the parking queue is missing.
+	*/
+	DelayBackendQueues
+)
+
+// DelayBackend defines how delayed and scheduled messages are implemented.
+type DelayBackend uint8
+
+func (p *publisher) SetDelayBackend(backend DelayBackend) {
+	p.delayMutex.Lock()
+	defer p.delayMutex.Unlock()
+
+	p.delayBackend = backend
+}
+
+// delayed returns the publishing to be sent in place of a delayed publishing, declaring the delayed exchange or
+// parking queue it is routed through.
+func (p *publisher) delayed(conn connection.Connection, publishing *Publishing, backend DelayBackend) (*Publishing,
+	error) {
+	delay := time.Until(publishing.DeliverAt)
+	if publishing.DeliverAt.IsZero() || delay <= 0 {
+		return publishing, nil
+	}
+
+	if backend == DelayBackendPlugin && publishing.Exchange != "" {
+		return p.delayedByPlugin(conn, publishing, delay)
+	}
+
+	return p.delayedByQueue(conn, publishing, delay)
+}
+
+// getDelayBackend returns the delay backend, resolving DelayBackendAuto by probing the broker. It must be called
+// without holding the publisher mutex, since the probe may wait for the broker.
+func (p *publisher) getDelayBackend(ctx context.Context) DelayBackend {
+	p.delayMutex.Lock()
+	backend := p.delayBackend
+	p.delayMutex.Unlock()
+
+	if backend != DelayBackendAuto {
+		return backend
+	}
+
+	isEnabled, err := p.isDelayedMessagePluginEnabled(ctx)
+	if err != nil {
+		return DelayBackendQueues
+	}
+
+	backend = DelayBackendQueues
+	if isEnabled {
+		backend = DelayBackendPlugin
+	}
+
+	p.delayMutex.Lock()
+	defer p.delayMutex.Unlock()
+
+	if p.delayBackend == DelayBackendAuto {
+		p.delayBackend = backend
+	}
+
+	return p.delayBackend
+}
+
+/*
+	isDelayedMessagePluginEnabled declares a probe exchange of type "x-delayed-message". Since the broker closes the
+	connection when declaring an unknown exchange type, the probe runs on its own connection.
+
+	It returns an error when the probe is inconclusive, e.g. the broker can't be reached.
+*/
+func (p *publisher) isDelayedMessagePluginEnabled(ctx context.Context) (bool, error) {
+	options := *p.connectionOptions
+	options.BackoffStrategy = connection.NewCappedBackoff(connection.NewConstantBackoff(0), 0)
+
+	conn, err := connection.NewConnectionContext(ctx, &options)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	channel := conn.GetChannel()
+	err = channel.ExchangeDeclare(delayProbeExchange, delayedExchangeType, false, true, false, false,
+		map[string]interface{}{delayedTypeArg: exchangeTypeFanout})
+
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp.CommandInvalid {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	_ = channel.ExchangeDelete(delayProbeExchange, false, false)
+	return true, nil
+}
+
+func (p *publisher) delayedByPlugin(conn connection.Connection, publishing *Publishing, delay time.Duration) (
+	*Publishing, error) {
+	exchange := delayedExchangePrefix + publishing.Exchange
+	err := p.declareDelayTopology(conn, exchange, 0, &topology.Topology{
+		Exchanges: []topology.Exchange{{
+			Name:    exchange,
+			Type:    delayedExchangeType,
+			Durable: true,
+			Args:    map[string]interface{}{delayedTypeArg: exchangeTypeFanout},
+		}},
+		ExchangeBindings: []topology.ExchangeBinding{{
+			Destination: publishing.Exchange,
+			Source:      exchange,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delayed := *publishing
+	delayed.Exchange = exchange
+	delayed.Mandatory = false // the plugin routes the message once the delay elapses, so it would always be returned
+	delayed.Message.Headers = mergeHeaders(publishing.Message.Headers,
+		map[string]interface{}{delayHeader: delay.Milliseconds()})
+	return &delayed, nil
+}
+
+func (p *publisher) delayedByQueue(conn connection.Connection, publishing *Publishing, delay time.Duration) (
+	*Publishing, error) {
+	delay = ((delay + parkingQueueGranularity - 1) / parkingQueueGranularity) * parkingQueueGranularity
+	queue := fmt.Sprintf("%s%s.%s.%d", parkingQueuePrefix, publishing.Exchange, publishing.RoutingKey,
+		delay.Milliseconds())
+	err := p.declareDelayTopology(conn, queue, parkingQueueRedeclareInterval, &topology.Topology{
+		Queues: []topology.Queue{{
+			Name:    queue,
+			Durable: true,
+			Args: map[string]interface{}{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    publishing.Exchange,
+				"x-dead-letter-routing-key": publishing.RoutingKey,
+				"x-expires":                 (delay + parkingQueueExpiration).Milliseconds(),
+			},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delayed := *publishing
+	delayed.Exchange = ""
+	delayed.RoutingKey = queue
+	delayed.Mandatory = true
+	delayed.Message.Expiration = ""
+	return &delayed, nil
+}
+
+// declareDelayTopology declares the delayed exchange or parking queue once per connection, and again once the
+// declaration is older than maxAge (zero means never).
+func (p *publisher) declareDelayTopology(conn connection.Connection, name string, maxAge time.Duration,
+	t *topology.Topology) error {
+	declaredAt, isDeclared := p.declaredDelays[name]
+	if isDeclared && (maxAge == 0 || time.Since(declaredAt) < maxAge) {
+		return nil
+	}
+
+	if err := t.Apply(conn); err != nil {
+		return err
+	}
+
+	p.declaredDelays[name] = time.Now()
+	return nil
+}
+
+func (p *Publishing) isDelayed() bool {
+	return !p.DeliverAt.IsZero()
+}
+
+func mergeHeaders(headers, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(headers)+len(overrides))
+	for key, value := range headers {
+		merged[key] = value
+	}
+
+	for key, value := range overrides {
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,51 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maykonlf/pubsub"
+)
+
+// ErrPersistenceRequired is returned when publishing a transient message to a durable exchange while the publisher
+// requires persistence (see WithRequiredPersistence).
+var ErrPersistenceRequired = errors.New("publisher: persistent delivery mode required by durable exchange")
+
+// getDeliveryMode returns the message delivery mode, or the publisher default one when the message has none, after
+// validating it.
+func (p *publisher) getDeliveryMode(m pubsub.Message, exchange string) (pubsub.DeliveryMode, error) {
+	mode := m.DeliveryMode()
+	if !m.HasDeliveryMode() && p.deliveryMode != 0 {
+		mode = p.deliveryMode
+	}
+
+	if err := mode.Validate(); err != nil {
+		return 0, err
+	}
+
+	if p.isPersistenceRequired && mode != pubsub.Persistent && p.isDurableExchange(exchange) {
+		return 0, fmt.Errorf("%w: exchange %q", ErrPersistenceRequired, exchange)
+	}
+
+	return mode, nil
+}
+
+// isDurableExchange returns true when the exchange is declared as durable by the publisher options or topology.
+// Exchanges declared elsewhere are unknown to the publisher and considered not durable.
+func (p *publisher) isDurableExchange(name string) bool {
+	for _, exchange := range p.exchanges {
+		if exchange.Name == name {
+			return exchange.Durable
+		}
+	}
+
+	if p.topology != nil {
+		for _, exchange := range p.topology.Exchanges {
+			if exchange.Name == name {
+				return exchange.Durable
+			}
+		}
+	}
+
+	return false
+}
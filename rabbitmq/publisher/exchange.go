@@ -0,0 +1,41 @@
+package publisher
+
+import "github.com/maykonlf/pubsub/rabbitmq/topology"
+
+const (
+	exchangeTypeFanout = "fanout"
+	exchangeTypeTopic  = "topic"
+)
+
+// Exchange contains the settings of an exchange declared by the publisher. See topology.Exchange for details.
+type Exchange = topology.Exchange
+
+// exchangesTopology returns the topology of the exchanges declared by publisher options, with their alternate
+// exchanges set and a queue bound to each alternate exchange.
+func (p *publisher) exchangesTopology() *topology.Topology {
+	t := &topology.Topology{}
+	for _, exchange := range p.exchanges {
+		declared := *exchange
+		if alternate, found := p.alternateExchanges[exchange.Name]; found && declared.AlternateExchange == "" {
+			declared.AlternateExchange = alternate
+		}
+
+		t.Exchanges = append(t.Exchanges, declared)
+		if p.isAlternateExchange(exchange.Name) {
+			t.Queues = append(t.Queues, topology.Queue{Name: exchange.Name, Durable: true})
+			t.Bindings = append(t.Bindings, topology.Binding{Queue: exchange.Name, Exchange: exchange.Name})
+		}
+	}
+
+	return t
+}
+
+func (p *publisher) isAlternateExchange(name string) bool {
+	for _, alternate := range p.alternateExchanges {
+		if alternate == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/schema"
+)
+
+// prepare returns the message to publish: validated against its schema (see WithSchemaRegistry), compressed (see
+// WithCompression) then transformed by the middlewares in the order they were added.
+func (p *publisher) prepare(ctx context.Context, m pubsub.Message) (pubsub.Message, error) {
+	if p.schemaRegistry != nil {
+		if err := schema.Validate(ctx, p.schemaRegistry, m); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := p.compress(m)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, middleware := range p.middlewares {
+		if m, err = middleware.Publish(ctx, m); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (p *publisher) AddMiddleware(middleware pubsub.Middleware) {
+	p.middlewares = append(p.middlewares, middleware)
+}
+
+func (p *publisher) SetSchemaRegistry(registry schema.Registry) {
+	p.schemaRegistry = registry
+}
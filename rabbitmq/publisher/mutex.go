@@ -0,0 +1,26 @@
+package publisher
+
+import "context"
+
+// contextMutex is a mutual exclusion lock which can stop waiting for the lock when a context is done.
+type contextMutex struct {
+	lock chan struct{}
+}
+
+func newContextMutex() *contextMutex {
+	return &contextMutex{lock: make(chan struct{}, 1)}
+}
+
+// Lock waits for the lock until it is acquired or ctx is done.
+func (m *contextMutex) Lock(ctx context.Context) error {
+	select {
+	case m.lock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *contextMutex) Unlock() {
+	<-m.lock
+}
@@ -1,3 +1,196 @@
 package publisher
 
+import (
+	"time"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/compression"
+	"github.com/maykonlf/pubsub/envelope"
+	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/maykonlf/pubsub/rabbitmq/topology"
+	"github.com/maykonlf/pubsub/schema"
+)
+
+// Option is a publisher option used to customize the publisher.
 type Option func(p Publisher)
+
+// WithTopology declares the given topology before the first publish and again on every reconnection, so the target
+// exchanges exist even when no consumer has declared them yet.
+func WithTopology(t *topology.Topology) Option {
+	return func(p Publisher) {
+		p.SetTopology(t)
+	}
+}
+
+// WithConnectionOptions set the connection options (TLS, authentication, heartbeat, etc). When options.URI is empty
+// the publisher URI is used.
+func WithConnectionOptions(options *connection.Options) Option {
+	return func(p Publisher) {
+		p.SetConnectionOptions(options)
+	}
+}
+
+// WithBuffer buffers the publishings made while the broker is unreachable in the given spool, holding up to capacity
+// publishings (zero means unbounded) and applying the overflow policy when full. Buffered publishings are sent after
+// the reconnection, dropping the ones which can't be read from the spool or are rejected by the broker.
+func WithBuffer(spool Spool, capacity int, policy OverflowPolicy) Option {
+	return func(p Publisher) {
+		p.SetBuffer(spool, capacity, policy)
+	}
+}
+
+// WithMemoryBuffer buffers up to capacity publishings in memory while the broker is unreachable. See WithBuffer.
+func WithMemoryBuffer(capacity int, policy OverflowPolicy) Option {
+	return WithBuffer(NewMemorySpool(), capacity, policy)
+}
+
+// WithBlockedTimeout set how long Publish waits for the broker to unblock the connection (e.g. after a memory or disk
+// alarm) before failing with connection.ErrBrokerBlocked. By default Publish fails fast while blocked.
+func WithBlockedTimeout(timeout time.Duration) Option {
+	return func(p Publisher) {
+		p.SetBlockedTimeout(timeout)
+	}
+}
+
+// WithDeliveryMode set the delivery mode of the published messages which have none set (transient by default).
+func WithDeliveryMode(mode pubsub.DeliveryMode) Option {
+	return func(p Publisher) {
+		p.SetDeliveryMode(mode)
+	}
+}
+
+// WithRequiredPersistence rejects, with ErrPersistenceRequired, the transient messages published to durable exchanges
+// declared by the publisher (see WithExchange and WithTopology), so important events can't be published transient by
+// mistake.
+func WithRequiredPersistence() Option {
+	return func(p Publisher) {
+		p.SetRequirePersistence(true)
+	}
+}
+
+// WithCompression compresses the bodies of at least threshold bytes with the given codec (e.g. compression.Gzip),
+// setting the message content encoding accordingly. Messages which already have a content encoding are published
+// as they are.
+func WithCompression(codec compression.Codec, threshold int) Option {
+	return func(p Publisher) {
+		p.SetCompression(codec, threshold)
+	}
+}
+
+// WithMiddleware transforms the published messages with the given middlewares (e.g. claimcheck.New), applied in
+// order after compression.
+func WithMiddleware(middlewares ...pubsub.Middleware) Option {
+	return func(p Publisher) {
+		for _, middleware := range middlewares {
+			p.AddMiddleware(middleware)
+		}
+	}
+}
+
+// WithEncryption encrypts the published bodies with the key encryption key identified by keyID. See
+// envelope.NewEncryption.
+func WithEncryption(provider envelope.KeyProvider, keyID string) Option {
+	return WithMiddleware(envelope.NewEncryption(provider, keyID))
+}
+
+// WithSigning signs the published messages body and given properties with the key identified by keyID. Add it after
+// WithEncryption to sign the encrypted bodies. See envelope.NewSigning.
+func WithSigning(signer envelope.Signer, keyID string, properties ...envelope.Property) Option {
+	return WithMiddleware(envelope.NewSigning(signer, keyID, properties...))
+}
+
+// WithSchemaRegistry refuses to publish the messages whose body doesn't match the schema registered for their type
+// and version (see schema.Validate).
+func WithSchemaRegistry(registry schema.Registry) Option {
+	return func(p Publisher) {
+		p.SetSchemaRegistry(registry)
+	}
+}
+
+/*
+	WithMaxPriority set the max priority of the queues receiving the messages published to the exchange, overriding
+	the one learned from the topology (the lowest max priority of the priority queues bound to the exchange). AMQP
+	passive declarations don't report the queue arguments, so the max priority of queues declared elsewhere (e.g. with
+	subscriber.WithDurablePriorityQueue) must be given here.
+
+	It doesn't apply to the default exchange (""), which routes to the queue named by the routing key. See
+	WithQueueMaxPriority.
+*/
+func WithMaxPriority(exchange string, maxPriority uint8) Option {
+	return func(p Publisher) {
+		p.SetMaxPriority(exchange, maxPriority)
+	}
+}
+
+// WithQueueMaxPriority set the max priority of a queue receiving messages published to the default exchange (with
+// the queue name as routing key), overriding the one learned from the topology.
+func WithQueueMaxPriority(queue string, maxPriority uint8) Option {
+	return func(p Publisher) {
+		p.SetQueueMaxPriority(queue, maxPriority)
+	}
+}
+
+// WithPriorityPolicy set how priorities above the target queue max priority are handled (clamped by default).
+func WithPriorityPolicy(policy PriorityPolicy) Option {
+	return func(p Publisher) {
+		p.SetPriorityPolicy(policy)
+	}
+}
+
+/*
+	WithExchange declares an exchange on connect and again on every reconnection, so producers can start before any
+	consumer declared it without their first messages vanishing.
+
+	Messages which can't be routed to any queue (e.g. before a consumer binds its queue) are still discarded by the
+	broker, unless published as mandatory (see PublishOptions) or to an exchange with an alternate exchange (see
+	WithAlternateExchange).
+*/
+func WithExchange(exchange *Exchange) Option {
+	return func(p Publisher) {
+		p.AddExchange(exchange)
+	}
+}
+
+// WithDurableTopicExchange declares a durable topic exchange. See WithExchange for the unroutable messages handling.
+func WithDurableTopicExchange(name string) Option {
+	return WithExchange(&Exchange{
+		Name:    name,
+		Type:    exchangeTypeTopic,
+		Durable: true,
+	})
+}
+
+// WithDurableFanoutExchange declares a durable fanout exchange. See WithExchange.
+func WithDurableFanoutExchange(name string) Option {
+	return WithExchange(&Exchange{
+		Name:    name,
+		Type:    exchangeTypeFanout,
+		Durable: true,
+	})
+}
+
+/*
+	WithAlternateExchange declares a durable fanout alternate exchange receiving the messages published to the given
+	exchange (declared with WithExchange or similar options) which can't be routed to any queue.
+
+	A durable queue named after the alternate exchange is declared and bound to it, keeping the unroutable messages
+	until consumed.
+*/
+func WithAlternateExchange(exchange, alternate string) Option {
+	return func(p Publisher) {
+		p.AddExchange(&Exchange{
+			Name:    alternate,
+			Type:    exchangeTypeFanout,
+			Durable: true,
+		})
+		p.SetAlternateExchange(exchange, alternate)
+	}
+}
+
+// WithDelayBackend set how delayed and scheduled messages (see pubsub.Message SetDelay and SetDeliverAt) are
+// implemented. See DelayBackend for details.
+func WithDelayBackend(backend DelayBackend) Option {
+	return func(p Publisher) {
+		p.SetDelayBackend(backend)
+	}
+}
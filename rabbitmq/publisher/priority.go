@@ -0,0 +1,96 @@
+package publisher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maykonlf/pubsub"
+)
+
+// ErrPriorityOutOfRange is returned when publishing a message with a priority above the target queue max priority
+// while the publisher rejects them (see PriorityReject).
+var ErrPriorityOutOfRange = errors.New("publisher: priority out of range")
+
+// PriorityPolicy defines how priorities above the target queue max priority are handled.
+type PriorityPolicy int
+
+const (
+	// PriorityClamp publishes the message with the queue max priority (default).
+	PriorityClamp PriorityPolicy = iota
+
+	// PriorityReject refuses to publish the message with ErrPriorityOutOfRange.
+	PriorityReject
+)
+
+// getPriority returns the message priority, mapping its priority level onto the target max priority (see
+// pubsub.PriorityLevel) and applying the priority policy to priorities above it.
+func (p *publisher) getPriority(m pubsub.Message, exchange, routingKey string) (uint8, error) {
+	maxPriority, isKnown := p.getMaxPriority(exchange, routingKey)
+	if level := m.PriorityLevel(); level != 0 {
+		if !isKnown {
+			maxPriority = pubsub.DefaultMaxPriority
+		}
+
+		return level.Priority(maxPriority), nil
+	}
+
+	priority := m.Priority()
+	if !isKnown || priority <= maxPriority {
+		return priority, nil
+	}
+
+	if p.priorityPolicy == PriorityReject {
+		if exchange == "" {
+			return 0, fmt.Errorf("%w: %d above max priority %d of queue %q", ErrPriorityOutOfRange, priority,
+				maxPriority, routingKey)
+		}
+
+		return 0, fmt.Errorf("%w: %d above max priority %d of exchange %q", ErrPriorityOutOfRange, priority,
+			maxPriority, exchange)
+	}
+
+	return maxPriority, nil
+}
+
+// getMaxPriority returns the max priority of the queues receiving the exchange messages, set by WithMaxPriority or
+// learned from the topology (see topology.Topology.MaxPriority). The default exchange routes to the queue named by
+// the routing key, so its max priority is the queue one, set by WithQueueMaxPriority or learned from the topology.
+func (p *publisher) getMaxPriority(exchange, routingKey string) (uint8, bool) {
+	if exchange == "" {
+		return p.getQueueMaxPriority(routingKey)
+	}
+
+	if maxPriority, found := p.maxPriorities[exchange]; found {
+		return maxPriority, true
+	}
+
+	if p.topology != nil {
+		return p.topology.MaxPriority(exchange)
+	}
+
+	return 0, false
+}
+
+func (p *publisher) getQueueMaxPriority(queue string) (uint8, bool) {
+	if maxPriority, found := p.queueMaxPriorities[queue]; found {
+		return maxPriority, true
+	}
+
+	if p.topology != nil {
+		return p.topology.QueueMaxPriority(queue)
+	}
+
+	return 0, false
+}
+
+func (p *publisher) SetMaxPriority(exchange string, maxPriority uint8) {
+	p.maxPriorities[exchange] = maxPriority
+}
+
+func (p *publisher) SetQueueMaxPriority(queue string, maxPriority uint8) {
+	p.queueMaxPriorities[queue] = maxPriority
+}
+
+func (p *publisher) SetPriorityPolicy(policy PriorityPolicy) {
+	p.priorityPolicy = policy
+}
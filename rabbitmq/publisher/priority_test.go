@@ -0,0 +1,77 @@
+package publisher
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/rabbitmq"
+	"github.com/maykonlf/pubsub/rabbitmq/topology"
+)
+
+func TestGetPriority(t *testing.T) {
+	options := []Option{
+		WithTopology(&topology.Topology{
+			Queues: []topology.Queue{
+				{Name: "tasks", Args: map[string]interface{}{"x-max-priority": 5}},
+				{Name: "jobs", Args: map[string]interface{}{"x-max-priority": 10}},
+			},
+			Bindings: []topology.Binding{{Queue: "tasks", Exchange: "work"}, {Queue: "jobs", Exchange: "work"}},
+		}),
+		WithMaxPriority("events", 3),
+		WithMaxPriority("", 1),
+		WithQueueMaxPriority("emails", 2),
+	}
+
+	tests := []struct {
+		name       string
+		exchange   string
+		routingKey string
+		priority   uint8
+		level      pubsub.PriorityLevel
+		want       uint8
+	}{
+		{name: "exchange from topology", exchange: "work", priority: 9, want: 5},
+		{name: "exchange from option", exchange: "events", priority: 9, want: 3},
+		{name: "unknown exchange", exchange: "other", priority: 9, want: 9},
+		{name: "default exchange queue from topology", routingKey: "jobs", priority: 9, want: 9},
+		{name: "default exchange queue from option", routingKey: "emails", priority: 9, want: 2},
+		{name: "default exchange unknown queue", routingKey: "other", priority: 9, want: 9},
+		{name: "level onto exchange max priority", exchange: "work", level: pubsub.PriorityCritical, want: 5},
+		{name: "level onto queue max priority", routingKey: "jobs", level: pubsub.PriorityHigh, want: 7},
+		{name: "level onto default max priority", exchange: "other", level: pubsub.PriorityNormal, want: 3},
+	}
+
+	p := NewPublisher("amqp://localhost", options...).(*publisher)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := rabbitmq.NewMessage().SetPriority(test.priority)
+			if test.level != 0 {
+				message.SetPriorityLevel(test.level)
+			}
+
+			got, err := p.getPriority(message, test.exchange, test.routingKey)
+			if err != nil {
+				t.Fatalf("getPriority() error = %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("getPriority() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetPriorityReject(t *testing.T) {
+	p := NewPublisher("amqp://localhost", WithQueueMaxPriority("emails", 2),
+		WithPriorityPolicy(PriorityReject)).(*publisher)
+
+	_, err := p.getPriority(rabbitmq.NewMessage().SetPriority(3), "", "emails")
+	if !errors.Is(err, ErrPriorityOutOfRange) {
+		t.Errorf("getPriority() error = %v, want %v", err, ErrPriorityOutOfRange)
+	}
+
+	if _, err := p.getPriority(rabbitmq.NewMessage().SetPriority(2), "", "emails"); err != nil {
+		t.Errorf("getPriority() error = %v", err)
+	}
+}
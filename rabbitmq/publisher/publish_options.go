@@ -0,0 +1,66 @@
+package publisher
+
+import "time"
+
+// PublishOptions contains per publishing settings.
+type PublishOptions struct {
+	/*
+		RoutingKeys are the publishing routing keys.
+
+		The message is published once per routing key (or once with an empty routing key when none is given).
+	*/
+	RoutingKeys []string
+
+	/*
+		Mandatory asks the broker to return the message when it can't be routed to any queue, failing the publishing
+		with ErrUnroutable.
+
+		Mandatory publishings always wait for the broker confirmation (see Confirm).
+	*/
+	Mandatory bool
+
+	/*
+		Confirm waits for the broker to confirm the publishing was handled (e.g. persisted to disk for durable queues
+		and persistent messages), failing with ErrNacked when the broker rejects it.
+
+		Confirmed publishings are never buffered (see WithBuffer), they fail while the broker is unreachable.
+	*/
+	Confirm bool
+
+	/*
+		AlternateExchange is an exchange the message is published to when the broker returns it as unroutable.
+
+		It implies a mandatory publishing.
+	*/
+	AlternateExchange string
+
+	// Headers are headers added to the message headers (overriding the ones with the same key) for this publishing.
+	Headers map[string]interface{}
+
+	// Timeout limits the time spent publishing (waiting for the connection, other publishings and confirmations).
+	Timeout time.Duration
+}
+
+func (o *PublishOptions) getRoutingKeys() []string {
+	if len(o.RoutingKeys) == 0 {
+		return []string{""}
+	}
+
+	return o.RoutingKeys
+}
+
+func (o *PublishOptions) isMandatory() bool {
+	return o.Mandatory || o.AlternateExchange != ""
+}
+
+func (o *PublishOptions) requiresConfirm() bool {
+	return o.Confirm || o.isMandatory()
+}
+
+func (o *PublishOptions) getHeaders(headers map[string]interface{}) map[string]interface{} {
+	if len(o.Headers) == 0 {
+		return headers
+	}
+
+	return mergeHeaders(headers, o.Headers)
+}
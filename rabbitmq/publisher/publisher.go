@@ -1,23 +1,62 @@
 package publisher
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/compression"
+	"github.com/maykonlf/pubsub/rabbitmq"
 	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/maykonlf/pubsub/rabbitmq/topology"
+	"github.com/maykonlf/pubsub/schema"
 	"github.com/streadway/amqp"
 	"sync"
 	"time"
 )
 
+// ErrNotConnected is returned by Health before the publisher connects to the broker.
+var ErrNotConnected = errors.New("publisher: not connected")
+
 type Publisher interface {
 	pubsub.Publisher
+	SetTopology(t *topology.Topology)
+	AddExchange(exchange *Exchange)
+	SetAlternateExchange(exchange, alternate string)
+	SetDelayBackend(backend DelayBackend)
+	SetConnectionOptions(options *connection.Options)
+	SetBuffer(spool Spool, capacity int, policy OverflowPolicy)
+	SetBlockedTimeout(timeout time.Duration)
+	SetDeliveryMode(mode pubsub.DeliveryMode)
+	SetRequirePersistence(isRequired bool)
+	SetCompression(codec compression.Codec, threshold int)
+	AddMiddleware(middleware pubsub.Middleware)
+	SetSchemaRegistry(registry schema.Registry)
+	SetMaxPriority(exchange string, maxPriority uint8)
+	SetQueueMaxPriority(queue string, maxPriority uint8)
+	SetPriorityPolicy(policy PriorityPolicy)
+
+	// Health returns nil when the publisher can publish, ErrNotConnected before it connects,
+	// connection.ErrBrokerBlocked while the broker blocks the connection, or the connection error once it gave up
+	// reconnecting. It never waits for the ongoing publishings.
+	Health() error
+
+	// PublishWithOptions publishes a message to a topic with the given options. See PublishOptions for details.
+	PublishWithOptions(ctx context.Context, message pubsub.Message, topic string, options *PublishOptions) error
 }
 
 // NewPublisher returns a new RabbitMQ publisher.
 func NewPublisher(uri string, options ...Option) Publisher {
 	publisher := &publisher{
-		mutex:             &sync.Mutex{},
-		connectionOptions: &connection.Options{URI: uri},
+		mutex:              newContextMutex(),
+		connMutex:          &sync.RWMutex{},
+		delayMutex:         &sync.Mutex{},
+		confirms:           newConfirmTracker(),
+		alternateExchanges: map[string]string{},
+		declaredDelays:     map[string]time.Time{},
+		maxPriorities:      map[string]uint8{},
+		queueMaxPriorities: map[string]uint8{},
+		connectionOptions:  &connection.Options{URI: uri},
 	}
 
 	for _, optionFunction := range options {
@@ -28,27 +67,198 @@ func NewPublisher(uri string, options ...Option) Publisher {
 }
 
 type publisher struct {
-	mutex             *sync.Mutex
-	connectionOptions *connection.Options
-	conn              connection.Connection
+	mutex                 *contextMutex
+	connMutex             *sync.RWMutex
+	connectionOptions     *connection.Options
+	conn                  connection.Connection
+	topology              *topology.Topology
+	exchanges             []*Exchange
+	alternateExchanges    map[string]string
+	isTopologyDeclared    bool
+	buffer                *buffer
+	blockedTimeout        time.Duration
+	confirms              *confirmTracker
+	delayMutex            *sync.Mutex
+	delayBackend          DelayBackend
+	declaredDelays        map[string]time.Time
+	deliveryMode          pubsub.DeliveryMode
+	isPersistenceRequired bool
+	compression           compression.Codec
+	compressionThreshold  int
+	middlewares           []pubsub.Middleware
+	schemaRegistry        schema.Registry
+	maxPriorities         map[string]uint8
+	queueMaxPriorities    map[string]uint8
+	priorityPolicy        PriorityPolicy
 }
 
-// Publish publishes a message to a topic (and optionally to a routing key).
+// Publish publishes a message to a topic, once per given routing key.
 func (p *publisher) Publish(m pubsub.Message, topic string, routingKey ...string) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+	return p.PublishContext(context.Background(), m, topic, routingKey...)
+}
 
-	return p.getConnection().GetChannel().Publish(
-		topic,
-		p.extractFirstRoutingKeyOrDefault(routingKey),
-		false,
-		false,
-		amqp.Publishing{
-			Headers:         m.Headers(),
+// PublishContext publishes a message to a topic, once per given routing key, giving up when ctx is done. See
+// PublishWithOptions for details.
+func (p *publisher) PublishContext(ctx context.Context, m pubsub.Message, topic string, routingKey ...string) error {
+	return p.PublishWithOptions(ctx, m, topic, &PublishOptions{RoutingKeys: routingKey})
+}
+
+/*
+	PublishWithOptions publishes a message to a topic with the given options (routing keys, mandatory, confirm, etc).
+
+	Messages without delivery mode are published with the publisher default one (see WithDeliveryMode). Invalid
+	delivery modes are rejected with a pubsub.ErrInvalidDeliveryMode wrapped error, and transient messages sent to
+	durable exchanges with ErrPersistenceRequired when persistence is required (see WithRequiredPersistence).
+
+	Priority levels are mapped onto the target queue priority range, and priorities above the queue max priority are
+	clamped or rejected according to the priority policy (see WithMaxPriority, WithQueueMaxPriority and
+	WithPriorityPolicy).
+
+	Messages whose body doesn't match their schema (see WithSchemaRegistry) are refused with a schema.ErrInvalidMessage
+	wrapped error.
+
+	The message is compressed (see WithCompression) and transformed by the middlewares (see WithMiddleware) once,
+	before being published to each routing key.
+
+	Headers are validated before publishing: values which can't be encoded in an AMQP table are rejected with a
+	rabbitmq.ErrInvalidHeader wrapped error.
+
+	It gives up when ctx is done while waiting for other publishings, for the connection, for a blocked connection or
+	for the broker confirmations. The trace context carried by ctx is propagated into the message headers.
+
+	When a publish buffer is set (see WithBuffer), publishings made while the broker is unreachable are buffered and
+	sent in order after the reconnection.
+*/
+func (p *publisher) PublishWithOptions(ctx context.Context, m pubsub.Message, topic string,
+	options *PublishOptions) error {
+	if options == nil {
+		options = &PublishOptions{}
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	m, err := p.prepare(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	var publishings []*Publishing
+	var confirmations []*confirmation
+	for _, routingKey := range options.getRoutingKeys() {
+		publishing, err := p.newPublishing(ctx, m, topic, routingKey, options)
+		if err != nil {
+			return err
+		}
+
+		pending, err := p.send(ctx, publishing, options.requiresConfirm())
+		if err != nil {
+			return err
+		}
+
+		publishings = append(publishings, publishing)
+		confirmations = append(confirmations, pending)
+	}
+
+	for i, pending := range confirmations {
+		if err := p.waitConfirmation(ctx, pending, publishings[i], options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// send publishes, or buffers when the connection is lost, the publishing. Publishings requiring confirmation are never
+// buffered. Delayed publishings are always confirmed, so a missing parking queue fails the publishing.
+func (p *publisher) send(ctx context.Context, publishing *Publishing, requireConfirm bool) (*confirmation, error) {
+	if p.buffer == nil || requireConfirm {
+		return p.publish(ctx, publishing, requireConfirm || publishing.isDelayed())
+	}
+
+	publish := func(publishing *Publishing) error {
+		return p.publishUnbuffered(ctx, publishing)
+	}
+
+	if err := p.buffer.flush(publish); err != nil {
+		if errors.Is(err, amqp.ErrClosed) {
+			return nil, p.buffer.push(ctx, publishing)
+		}
+
+		return nil, err
+	}
+
+	err := publish(publishing)
+	if errors.Is(err, amqp.ErrClosed) {
+		return nil, p.buffer.push(ctx, publishing)
+	}
+
+	return nil, err
+}
+
+// publishUnbuffered publishes a publishing which doesn't require confirmation, waiting for the confirmation of the
+// delayed ones.
+func (p *publisher) publishUnbuffered(ctx context.Context, publishing *Publishing) error {
+	pending, err := p.publish(ctx, publishing, publishing.isDelayed())
+	if err != nil || pending == nil {
+		return err
+	}
+
+	return pending.wait(ctx)
+}
+
+func (p *publisher) waitConfirmation(ctx context.Context, pending *confirmation, publishing *Publishing,
+	options *PublishOptions) error {
+	if pending == nil {
+		return nil
+	}
+
+	err := pending.wait(ctx)
+	if !errors.Is(err, ErrUnroutable) || options.AlternateExchange == "" {
+		return err
+	}
+
+	alternate := *publishing
+	alternate.Exchange = options.AlternateExchange
+	alternate.Mandatory = false
+	if pending, err = p.publish(ctx, &alternate, true); err != nil {
+		return err
+	}
+
+	return pending.wait(ctx)
+}
+
+func (p *publisher) newPublishing(ctx context.Context, m pubsub.Message, topic, routingKey string,
+	options *PublishOptions) (*Publishing, error) {
+	headers, err := rabbitmq.HeadersTable(options.getHeaders(pubsub.InjectTraceContext(ctx, m.Headers())))
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryMode, err := p.getDeliveryMode(m, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	priority, err := p.getPriority(m, topic, routingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Publishing{
+		Exchange:   topic,
+		RoutingKey: routingKey,
+		Mandatory:  options.isMandatory(),
+		DeliverAt:  getDeliverAt(m),
+		Message: amqp.Publishing{
+			Headers:         headers,
 			ContentType:     m.ContentType(),
 			ContentEncoding: m.ContentEncoding(),
-			DeliveryMode:    m.DeliveryMode(),
-			Priority:        m.Priority(),
+			DeliveryMode:    uint8(deliveryMode),
+			Priority:        priority,
 			CorrelationId:   m.CorrelationID().String(),
 			ReplyTo:         m.ReplyTo(),
 			Expiration:      p.getExpirationStringInMillisecondsOrDefault(m.Expiration()),
@@ -58,7 +268,84 @@ func (p *publisher) Publish(m pubsub.Message, topic string, routingKey ...string
 			UserId:          m.UserID(),
 			AppId:           m.AppID(),
 			Body:            m.Body(),
-		})
+		},
+	}, nil
+}
+
+func (p *publisher) publish(ctx context.Context, publishing *Publishing, requireConfirm bool) (*confirmation, error) {
+	var backend DelayBackend
+	if publishing.isDelayed() {
+		backend = p.getDelayBackend(ctx)
+	}
+
+	if err := p.mutex.Lock(ctx); err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+
+	conn, err := p.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := p.waitUnblocked(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	if publishing, err = p.delayed(conn, publishing, backend); err != nil {
+		return nil, err
+	}
+
+	channel := conn.GetChannel()
+	if err := p.confirms.prepare(channel, requireConfirm); err != nil {
+		return nil, err
+	}
+
+	pending := p.confirms.reserve(requireConfirm)
+	err = channel.Publish(
+		publishing.Exchange,
+		publishing.RoutingKey,
+		publishing.Mandatory,
+		false,
+		publishing.Message)
+	if err != nil {
+		p.confirms.release()
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// waitUnblocked waits until ctx deadline for the broker to unblock the connection. When ctx has no deadline it waits
+// up to the blocked timeout, failing fast with connection.ErrBrokerBlocked by default.
+func (p *publisher) waitUnblocked(ctx context.Context, conn connection.Connection) error {
+	if !conn.IsBlocked() {
+		return nil
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.blockedTimeout)
+		defer cancel()
+	}
+
+	if conn.WaitUnblocked(ctx) != nil {
+		return fmt.Errorf("%w: %s", connection.ErrBrokerBlocked, conn.BlockedReason())
+	}
+
+	return nil
+}
+
+func getDeliverAt(m pubsub.Message) time.Time {
+	if delay := m.Delay(); delay > 0 {
+		return time.Now().Add(delay)
+	}
+
+	return time.Time{}
 }
 
 func (p *publisher) getExpirationStringInMillisecondsOrDefault(expiration time.Duration) string {
@@ -69,18 +356,114 @@ func (p *publisher) getExpirationStringInMillisecondsOrDefault(expiration time.D
 	return ""
 }
 
-func (p *publisher) extractFirstRoutingKeyOrDefault(key []string) string {
-	if len(key) > 0 {
-		return key[0]
+func (p *publisher) SetTopology(t *topology.Topology) {
+	p.topology = t
+}
+
+func (p *publisher) AddExchange(exchange *Exchange) {
+	p.exchanges = append(p.exchanges, exchange)
+}
+
+func (p *publisher) SetAlternateExchange(exchange, alternate string) {
+	p.alternateExchanges[exchange] = alternate
+}
+
+func (p *publisher) SetConnectionOptions(options *connection.Options) {
+	if options.URI == "" {
+		options.URI = p.connectionOptions.URI
 	}
 
-	return ""
+	p.connectionOptions = options
+}
+
+func (p *publisher) SetBuffer(spool Spool, capacity int, policy OverflowPolicy) {
+	p.buffer = newBuffer(spool, capacity, policy)
+}
+
+func (p *publisher) SetBlockedTimeout(timeout time.Duration) {
+	p.blockedTimeout = timeout
+}
+
+func (p *publisher) SetDeliveryMode(mode pubsub.DeliveryMode) {
+	p.deliveryMode = mode
 }
 
-func (p *publisher) getConnection() connection.Connection {
+func (p *publisher) SetRequirePersistence(isRequired bool) {
+	p.isPersistenceRequired = isRequired
+}
+
+func (p *publisher) Health() error {
+	p.connMutex.RLock()
+	conn := p.conn
+	p.connMutex.RUnlock()
+
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	if err := conn.Err(); err != nil {
+		return err
+	}
+
+	if conn.IsBlocked() {
+		return fmt.Errorf("%w: %s", connection.ErrBrokerBlocked, conn.BlockedReason())
+	}
+
+	return nil
+}
+
+func (p *publisher) getConnection(ctx context.Context) (connection.Connection, error) {
 	if p.conn == nil {
-		p.conn = connection.NewConnection(p.connectionOptions)
+		conn, err := connection.NewConnectionContext(ctx, p.connectionOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		p.connMutex.Lock()
+		p.conn = conn
+		p.connMutex.Unlock()
+		p.conn.SetReconnectHooks(p.redeclareTopology, p.flushBuffer)
+	}
+
+	if !p.isTopologyDeclared {
+		if err := p.declareTopology(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.conn, nil
+}
+
+func (p *publisher) declareTopology() error {
+	if p.topology != nil {
+		if err := p.topology.Apply(p.conn); err != nil {
+			return err
+		}
+	}
+
+	if err := p.exchangesTopology().Apply(p.conn); err != nil {
+		return err
+	}
+
+	p.isTopologyDeclared = true
+	return nil
+}
+
+func (p *publisher) redeclareTopology() error {
+	_ = p.mutex.Lock(context.Background())
+	defer p.mutex.Unlock()
+
+	p.isTopologyDeclared = false
+	p.declaredDelays = map[string]time.Time{}
+	return p.declareTopology()
+}
+
+func (p *publisher) flushBuffer() error {
+	if p.buffer == nil {
+		return nil
 	}
 
-	return p.conn
+	return p.buffer.flush(func(publishing *Publishing) error {
+		return p.publishUnbuffered(context.Background(), publishing)
+	})
 }
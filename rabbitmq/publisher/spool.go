@@ -0,0 +1,218 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const spoolFileExtension = ".publishing"
+
+// ErrSpoolEmpty is returned when reading from an empty spool.
+var ErrSpoolEmpty = errors.New("publisher: spool is empty")
+
+func init() {
+	gob.Register(amqp.Table{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+	gob.Register(amqp.Decimal{})
+}
+
+// Publishing is a publishing waiting to be sent to the broker.
+type Publishing struct {
+	// Exchange is the exchange the message is published to.
+	Exchange string
+
+	// RoutingKey is the publishing routing key.
+	RoutingKey string
+
+	// Mandatory defines if the broker returns the message when it can't be routed to any queue.
+	Mandatory bool
+
+	// DeliverAt is the time the message should be delivered at, for delayed and scheduled messages.
+	DeliverAt time.Time
+
+	// Message is the AMQP message.
+	Message amqp.Publishing
+}
+
+/*
+	Spool is a FIFO storage of publishings, used to buffer publishings while the broker is unreachable.
+
+	Implementations don't need to be safe for concurrent use, the publisher buffer serializes the calls.
+*/
+type Spool interface {
+	// Push appends a publishing to the spool tail.
+	Push(publishing *Publishing) error
+
+	// Peek returns the publishing at the spool head, or ErrSpoolEmpty.
+	Peek() (*Publishing, error)
+
+	// Pop removes the publishing at the spool head.
+	Pop() error
+
+	// Len returns the number of spooled publishings.
+	Len() int
+}
+
+// NewMemorySpool returns an in-memory spool. Spooled publishings are lost if the process exits.
+func NewMemorySpool() Spool {
+	return &memorySpool{}
+}
+
+type memorySpool struct {
+	publishings []*Publishing
+}
+
+func (s *memorySpool) Push(publishing *Publishing) error {
+	s.publishings = append(s.publishings, publishing)
+	return nil
+}
+
+func (s *memorySpool) Peek() (*Publishing, error) {
+	if len(s.publishings) == 0 {
+		return nil, ErrSpoolEmpty
+	}
+
+	return s.publishings[0], nil
+}
+
+func (s *memorySpool) Pop() error {
+	if len(s.publishings) == 0 {
+		return ErrSpoolEmpty
+	}
+
+	s.publishings[0] = nil
+	s.publishings = s.publishings[1:]
+	return nil
+}
+
+func (s *memorySpool) Len() int {
+	return len(s.publishings)
+}
+
+/*
+	NewDiskSpool returns a spool storing each publishing as a file in the given directory, so spooled publishings
+	survive process restarts.
+
+	Publishings already spooled in the directory (e.g. by a previous process) are loaded and sent first. The directory
+	must not be shared by other spools.
+*/
+func NewDiskSpool(dir string) (Spool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("publisher: create spool dir: %w", err)
+	}
+
+	sequences, err := readSpoolSequences(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diskSpool{dir: dir, sequences: sequences}, nil
+}
+
+type diskSpool struct {
+	dir       string
+	sequences []uint64
+}
+
+func readSpoolSequences(dir string) ([]uint64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("publisher: read spool dir: %w", err)
+	}
+
+	var sequences []uint64
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, spoolFileExtension) {
+			continue
+		}
+
+		sequence, err := strconv.ParseUint(strings.TrimSuffix(name, spoolFileExtension), 10, 64)
+		if err == nil {
+			sequences = append(sequences, sequence)
+		}
+	}
+
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+	return sequences, nil
+}
+
+func (s *diskSpool) Push(publishing *Publishing) error {
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(publishing); err != nil {
+		return fmt.Errorf("publisher: encode spooled publishing: %w", err)
+	}
+
+	sequence := s.nextSequence()
+	temporaryPath := s.path(sequence) + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, data.Bytes(), 0600); err != nil {
+		return fmt.Errorf("publisher: write spooled publishing: %w", err)
+	}
+
+	if err := os.Rename(temporaryPath, s.path(sequence)); err != nil {
+		return fmt.Errorf("publisher: write spooled publishing: %w", err)
+	}
+
+	s.sequences = append(s.sequences, sequence)
+	return nil
+}
+
+func (s *diskSpool) Peek() (*Publishing, error) {
+	if len(s.sequences) == 0 {
+		return nil, ErrSpoolEmpty
+	}
+
+	data, err := ioutil.ReadFile(s.path(s.sequences[0]))
+	if err != nil {
+		return nil, fmt.Errorf("publisher: read spooled publishing: %w", err)
+	}
+
+	publishing := &Publishing{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(publishing); err != nil {
+		return nil, fmt.Errorf("publisher: decode spooled publishing: %w", err)
+	}
+
+	return publishing, nil
+}
+
+func (s *diskSpool) Pop() error {
+	if len(s.sequences) == 0 {
+		return ErrSpoolEmpty
+	}
+
+	if err := os.Remove(s.path(s.sequences[0])); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("publisher: remove spooled publishing: %w", err)
+	}
+
+	s.sequences = s.sequences[1:]
+	return nil
+}
+
+func (s *diskSpool) Len() int {
+	return len(s.sequences)
+}
+
+func (s *diskSpool) nextSequence() uint64 {
+	if len(s.sequences) == 0 {
+		return 1
+	}
+
+	return s.sequences[len(s.sequences)-1] + 1
+}
+
+func (s *diskSpool) path(sequence uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", sequence, spoolFileExtension))
+}
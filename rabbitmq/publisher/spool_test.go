@@ -0,0 +1,84 @@
+package publisher
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestDiskSpoolReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	publishings := []*Publishing{
+		{
+			Exchange:   "orders",
+			RoutingKey: "order.created",
+			Mandatory:  true,
+			DeliverAt:  timestamp.Add(time.Minute),
+			Message: amqp.Publishing{
+				Headers: amqp.Table{
+					"string": "value",
+					"int64":  int64(1),
+					"time":   timestamp,
+					"nested": amqp.Table{"array": []interface{}{"value", int32(2)}},
+				},
+				ContentType:  "application/json",
+				DeliveryMode: amqp.Persistent,
+				Priority:     5,
+				MessageId:    "id",
+				Timestamp:    timestamp,
+				Body:         []byte(`{"id": 1}`),
+			},
+		},
+		{Exchange: "orders", RoutingKey: "order.deleted", Message: amqp.Publishing{Body: []byte("body")}},
+	}
+
+	spool, err := NewDiskSpool(dir)
+	if err != nil {
+		t.Fatalf("NewDiskSpool() error = %v", err)
+	}
+
+	for _, publishing := range publishings {
+		if err := spool.Push(publishing); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	replayed, err := NewDiskSpool(dir)
+	if err != nil {
+		t.Fatalf("NewDiskSpool() error = %v", err)
+	}
+
+	if replayed.Len() != len(publishings) {
+		t.Fatalf("Len() = %d, want %d", replayed.Len(), len(publishings))
+	}
+
+	for _, want := range publishings {
+		got, err := replayed.Peek()
+		if err != nil {
+			t.Fatalf("Peek() error = %v", err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Peek() = %+v, want %+v", got, want)
+		}
+
+		if err := replayed.Pop(); err != nil {
+			t.Fatalf("Pop() error = %v", err)
+		}
+	}
+
+	if _, err := replayed.Peek(); !errors.Is(err, ErrSpoolEmpty) {
+		t.Errorf("Peek() error = %v, want %v", err, ErrSpoolEmpty)
+	}
+}
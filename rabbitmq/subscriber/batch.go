@@ -0,0 +1,192 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/streadway/amqp"
+)
+
+// ErrInvalidBatchSize is returned by SubscribeBatch when the given max batch size is lower than 1.
+var ErrInvalidBatchSize = errors.New("subscriber: batch max size must be greater than zero")
+
+// BatchHandler handles a batch of consumed messages. Returning an error negatively acknowledges the whole batch.
+type BatchHandler func(ctx context.Context, messages []pubsub.Message) error
+
+type batch struct {
+	messages   []pubsub.Message
+	deliveries []amqp.Delivery
+}
+
+func newBatch(maxSize int) *batch {
+	return &batch{
+		messages:   make([]pubsub.Message, 0, maxSize),
+		deliveries: make([]amqp.Delivery, 0, maxSize),
+	}
+}
+
+func (b *batch) add(delivery amqp.Delivery, message pubsub.Message) {
+	b.deliveries = append(b.deliveries, delivery)
+	b.messages = append(b.messages, message)
+}
+
+func (b *batch) size() int {
+	return len(b.deliveries)
+}
+
+func (b *batch) reset() {
+	*b = *newBatch(cap(b.deliveries))
+}
+
+func (b *batch) last() amqp.Delivery {
+	return b.deliveries[len(b.deliveries)-1]
+}
+
+/*
+	SubscribeBatch start consuming and delivery the consumed messages in batches to the given function.
+
+	Deliveries are accumulated until maxSize messages are consumed or maxWait elapses since the first message of the
+	batch arrived (a maxWait lower or equal than zero disables the time limit). The handler is called once per batch
+	and the whole batch is acknowledged when the handler returns nil, or negatively acknowledged (and requeued)
+	otherwise, using a single multiple-ack on the last delivery of the batch. The handler must not ack, nack or reject
+	the messages by itself.
+
+	The channel prefetch count is raised to maxSize when lower, so a full batch can always be delivered. Messages of a
+	partial batch are dropped on disconnection and redelivered by the broker after the reconnection.
+
+	SubscribeBatch blocks until ctx is done, then requeues the pending messages and returns the context error, or
+	until the connection gives up reconnecting, then returns the connection error. It returns the error of the queue
+	and bindings setup when it fails. The connection is closed once it returns.
+*/
+func (s *subscriber) SubscribeBatch(ctx context.Context, maxSize int, maxWait time.Duration,
+	handler BatchHandler) error {
+	if maxSize <= 0 {
+		return ErrInvalidBatchSize
+	}
+
+	s.setupBatchPrefetchQos(maxSize)
+	defer s.stopBatchConsume()
+	if err := s.setupSubscriber(); err != nil {
+		return err
+	}
+
+	if err := s.openConsumerChannel(); err != nil {
+		return err
+	}
+
+	s.getConnection().SetReconnectHooks(s.reconnectSubscriber)
+	return s.handleBatchConsume(ctx, newBatch(maxSize), maxSize, maxWait, handler)
+}
+
+func (s *subscriber) setupBatchPrefetchQos(maxSize int) {
+	if s.prefetchQos.Count >= maxSize {
+		return
+	}
+
+	s.prefetchQos = &PrefetchQos{
+		Count:    maxSize,
+		Size:     s.prefetchQos.Size,
+		IsGlobal: s.prefetchQos.IsGlobal,
+	}
+}
+
+func (s *subscriber) handleBatchConsume(ctx context.Context, b *batch, maxSize int, maxWait time.Duration,
+	handler BatchHandler) error {
+	var flushTimeout <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			s.nackBatch(b)
+			if err := s.cancelConsumer(); err == nil {
+				s.requeuePrefetched()
+			}
+
+			return ctx.Err()
+
+		case <-flushTimeout:
+			flushTimeout = nil
+			s.flushBatch(ctx, b, handler)
+
+		case delivery, isOpen := <-s.getDeliveryChannel():
+			if !isOpen {
+				flushTimeout = nil
+				b.reset()
+				if err := s.waitForReconnection(ctx); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			message, err := s.newMessage(ctx, delivery)
+			if err != nil {
+				s.settleFailedDelivery(delivery, err)
+				continue
+			}
+
+			b.add(delivery, message)
+			if b.size() == 1 && maxWait > 0 {
+				flushTimeout = time.After(maxWait)
+			}
+
+			if b.size() >= maxSize {
+				flushTimeout = nil
+				s.flushBatch(ctx, b, handler)
+			}
+		}
+	}
+}
+
+func (s *subscriber) flushBatch(ctx context.Context, b *batch, handler BatchHandler) {
+	if b.size() == 0 {
+		return
+	}
+
+	s.trackStreamOffset(b.last())
+	if err := handler(ctx, b.messages); err != nil {
+		s.nackBatch(b)
+		return
+	}
+
+	s.ackBatch(b)
+}
+
+func (s *subscriber) ackBatch(b *batch) {
+	if b.size() > 0 && !s.isAutoAck {
+		_ = b.last().Ack(true)
+	}
+
+	b.reset()
+}
+
+func (s *subscriber) nackBatch(b *batch) {
+	if b.size() > 0 && !s.isAutoAck {
+		_ = b.last().Nack(true, true)
+	}
+
+	b.reset()
+}
+
+func (s *subscriber) cancelConsumer() error {
+	return s.getConnection().GetChannel().Cancel(s.getConsumerTag(), false)
+}
+
+// stopBatchConsume removes the reconnect hooks and closes the connection once SubscribeBatch returns, so it is not
+// consuming (nor reconnecting) anymore.
+func (s *subscriber) stopBatchConsume() {
+	conn := s.getConnection()
+	conn.SetReconnectHooks()
+	_ = conn.Close()
+}
+
+// requeuePrefetched negatively acknowledges (and requeues) the deliveries prefetched before the consumer was
+// cancelled, until the delivery channel is closed.
+func (s *subscriber) requeuePrefetched() {
+	for delivery := range s.getDeliveryChannel() {
+		if !s.isAutoAck {
+			_ = delivery.Nack(false, true)
+		}
+	}
+}
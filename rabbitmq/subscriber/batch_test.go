@@ -0,0 +1,187 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/streadway/amqp"
+)
+
+var errGaveUp = errors.New("gave up")
+
+// fakeConnection is a connection which already gave up reconnecting, so the batch consume returns once the delivery
+// channel is closed.
+type fakeConnection struct {
+	done     chan struct{}
+	hooks    []func() error
+	isClosed bool
+}
+
+func newFakeConnection() *fakeConnection {
+	done := make(chan struct{})
+	close(done)
+	return &fakeConnection{done: done}
+}
+
+func (c *fakeConnection) GetConn() *amqp.Connection {
+	return nil
+}
+
+func (c *fakeConnection) GetChannel() *amqp.Channel {
+	return nil
+}
+
+func (c *fakeConnection) SetReconnectHooks(hooks ...func() error) {
+	c.hooks = hooks
+}
+
+func (c *fakeConnection) GetEndpoint() string {
+	return ""
+}
+
+func (c *fakeConnection) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *fakeConnection) Err() error {
+	return errGaveUp
+}
+
+func (c *fakeConnection) Close() error {
+	c.isClosed = true
+	return nil
+}
+
+func (c *fakeConnection) IsBlocked() bool {
+	return false
+}
+
+func (c *fakeConnection) BlockedReason() string {
+	return ""
+}
+
+func (c *fakeConnection) WaitUnblocked(ctx context.Context) error {
+	return nil
+}
+
+// acknowledger records the acknowledged delivery tags.
+type acknowledger struct {
+	mutex *sync.Mutex
+	acks  []uint64
+	nacks []uint64
+}
+
+func (a *acknowledger) Ack(tag uint64, multiple bool) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.acks = append(a.acks, tag)
+	return nil
+}
+
+func (a *acknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.nacks = append(a.nacks, tag)
+	return nil
+}
+
+func (a *acknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func TestHandleBatchConsume(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSize    int
+		maxWait    time.Duration
+		deliveries int
+		failing    int
+		wantSizes  []int
+		wantAcks   []uint64
+		wantNacks  []uint64
+	}{
+		{name: "max size", maxSize: 3, deliveries: 7, wantSizes: []int{3, 3}, wantAcks: []uint64{3, 6}},
+		{name: "flush interval", maxSize: 10, maxWait: 10 * time.Millisecond, deliveries: 2, wantSizes: []int{2},
+			wantAcks: []uint64{2}},
+		{name: "max size before flush interval", maxSize: 2, maxWait: time.Hour, deliveries: 5,
+			wantSizes: []int{2, 2}, wantAcks: []uint64{2, 4}},
+		{name: "failing handler", maxSize: 2, deliveries: 4, failing: 1, wantSizes: []int{2, 2},
+			wantAcks: []uint64{4}, wantNacks: []uint64{2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deliveries := make(chan amqp.Delivery)
+			s := NewSubscriber("amqp://localhost").(*subscriber)
+			s.conn = newFakeConnection()
+			s.messageDeliveryChannel = deliveries
+
+			flushed := make(chan int, test.deliveries)
+			failing := test.failing
+			handler := func(ctx context.Context, messages []pubsub.Message) error {
+				flushed <- len(messages)
+				if failing > 0 {
+					failing--
+					return errors.New("failed")
+				}
+
+				return nil
+			}
+
+			result := make(chan error)
+			go func() {
+				result <- s.handleBatchConsume(context.Background(), newBatch(test.maxSize), test.maxSize,
+					test.maxWait, handler)
+			}()
+
+			ack := &acknowledger{mutex: &sync.Mutex{}}
+			for tag := 1; tag <= test.deliveries; tag++ {
+				deliveries <- amqp.Delivery{Acknowledger: ack, DeliveryTag: uint64(tag)}
+			}
+
+			var sizes []int
+			for len(sizes) < len(test.wantSizes) {
+				select {
+				case size := <-flushed:
+					sizes = append(sizes, size)
+				case <-time.After(time.Second):
+					t.Fatalf("handler called with batches of %v, want %v", sizes, test.wantSizes)
+				}
+			}
+
+			close(deliveries)
+			if err := <-result; !errors.Is(err, errGaveUp) {
+				t.Errorf("handleBatchConsume() error = %v, want %v", err, errGaveUp)
+			}
+
+			if !reflect.DeepEqual(sizes, test.wantSizes) {
+				t.Errorf("handler called with batches of %v, want %v", sizes, test.wantSizes)
+			}
+
+			if !reflect.DeepEqual(ack.acks, test.wantAcks) || !reflect.DeepEqual(ack.nacks, test.wantNacks) {
+				t.Errorf("acks = %v, nacks = %v, want acks = %v, nacks = %v", ack.acks, ack.nacks, test.wantAcks,
+					test.wantNacks)
+			}
+		})
+	}
+}
+
+func TestStopBatchConsume(t *testing.T) {
+	conn := newFakeConnection()
+	s := NewSubscriber("amqp://localhost").(*subscriber)
+	s.conn = conn
+	conn.SetReconnectHooks(s.reconnectSubscriber)
+
+	s.stopBatchConsume()
+	if len(conn.hooks) != 0 || !conn.isClosed {
+		t.Errorf("stopBatchConsume() kept %d reconnect hooks, closed connection = %v", len(conn.hooks),
+			conn.isClosed)
+	}
+}
@@ -0,0 +1,78 @@
+package subscriber
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/compression"
+	"github.com/maykonlf/pubsub/rabbitmq"
+	"github.com/maykonlf/pubsub/schema"
+	"github.com/streadway/amqp"
+)
+
+// newMessage creates the message handed to the handler from the delivery: transformed by the middlewares in reverse
+// order, decompressed according to the content encoding, then validated against its schema. Content encodings without
+// registered codec (e.g. "utf-8") are left as they are.
+func (s *subscriber) newMessage(ctx context.Context, delivery amqp.Delivery) (pubsub.Message, error) {
+	var message pubsub.Message = rabbitmq.NewMessageFromDelivery(delivery)
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		var err error
+		if message, err = s.middlewares[i].Consume(ctx, message); err != nil {
+			return nil, err
+		}
+	}
+
+	if codec, found := compression.Lookup(message.ContentEncoding()); found {
+		body, err := codec.Decompress(message.Body())
+		if err != nil {
+			return nil, fmt.Errorf("%w: decompress %s: %v", pubsub.ErrUnprocessable, codec.Encoding(), err)
+		}
+
+		message.SetBody(body).SetContentEncoding("")
+	}
+
+	if err := s.validate(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (s *subscriber) validate(ctx context.Context, message pubsub.Message) error {
+	if s.schemaRegistry == nil {
+		return nil
+	}
+
+	err := schema.Validate(ctx, s.schemaRegistry, message)
+	if errors.Is(err, schema.ErrInvalidMessage) {
+		return fmt.Errorf("%w: %v", pubsub.ErrUnprocessable, err)
+	}
+
+	return err
+}
+
+// settleFailedDelivery settles a delivery which can't be handed to the handler: unprocessable ones (e.g. a corrupted
+// compressed body) are rejected, so they are dead-lettered instead of being redelivered forever, and the others are
+// requeued.
+func (s *subscriber) settleFailedDelivery(delivery amqp.Delivery, err error) {
+	if s.isAutoAck {
+		return
+	}
+
+	if errors.Is(err, pubsub.ErrUnprocessable) {
+		_ = delivery.Reject(false)
+		return
+	}
+
+	_ = delivery.Nack(false, true)
+}
+
+func (s *subscriber) AddMiddleware(middleware pubsub.Middleware) {
+	s.middlewares = append(s.middlewares, middleware)
+}
+
+func (s *subscriber) SetSchemaRegistry(registry schema.Registry) {
+	s.schemaRegistry = registry
+}
@@ -25,4 +25,64 @@ type Exchange struct {
 
 	// RoutingKey is the routingKey used to bind exchange to consumer queue (for topic exchanges).
 	RoutingKey string
+
+	// RoutingKeys are additional routing keys used to bind exchange to consumer queue, one binding per key.
+	RoutingKeys []string
+
+	// BindArgs are the args used to bind this exchange to the consumer queue (e.g. "x-match" and the matched headers
+	// for headers exchanges). They are merged over the Queue.QueueBindArgs.
+	BindArgs map[string]interface{}
+}
+
+func (e *Exchange) normalizeRoutingKeys() {
+	if len(e.RoutingKeys) == 0 || e.RoutingKey != "" {
+		e.addRoutingKey(e.RoutingKey)
+	}
+}
+
+func (e *Exchange) addRoutingKey(routingKey string) bool {
+	if e.hasRoutingKey(routingKey) {
+		return false
+	}
+
+	e.RoutingKeys = append(e.RoutingKeys, routingKey)
+	return true
+}
+
+func (e *Exchange) removeRoutingKey(routingKey string) bool {
+	for i, key := range e.RoutingKeys {
+		if key == routingKey {
+			e.RoutingKeys = append(e.RoutingKeys[:i:i], e.RoutingKeys[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Exchange) hasRoutingKey(routingKey string) bool {
+	for _, key := range e.RoutingKeys {
+		if key == routingKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Exchange) getBindArgs(queueBindArgs map[string]interface{}) map[string]interface{} {
+	if len(e.BindArgs) == 0 {
+		return queueBindArgs
+	}
+
+	args := make(map[string]interface{}, len(queueBindArgs)+len(e.BindArgs))
+	for key, value := range queueBindArgs {
+		args[key] = value
+	}
+
+	for key, value := range e.BindArgs {
+		args[key] = value
+	}
+
+	return args
 }
@@ -0,0 +1,28 @@
+package subscriber
+
+const headersMatchArg = "x-match"
+
+const (
+	// HeadersMatchAll forwards a message only when all the bound headers match the message headers.
+	HeadersMatchAll HeadersMatch = iota
+
+	// HeadersMatchAny forwards a message when at least one of the bound headers matches the message headers.
+	HeadersMatchAny
+)
+
+var mapHeadersMatchString = map[HeadersMatch]string{
+	HeadersMatchAll: "all",
+	HeadersMatchAny: "any",
+}
+
+// HeadersMatch represents the "x-match" mode of a headers exchange binding.
+type HeadersMatch uint8
+
+/*
+	String returns the HeadersMatch as string:
+		HeadersMatchAll => "all"
+		HeadersMatchAny => "any"
+*/
+func (m HeadersMatch) String() string {
+	return mapHeadersMatchString[m]
+}
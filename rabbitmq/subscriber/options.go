@@ -1,15 +1,69 @@
 package subscriber
 
+import (
+	"time"
+
+	"github.com/maykonlf/pubsub"
+	"github.com/maykonlf/pubsub/envelope"
+	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/maykonlf/pubsub/schema"
+)
+
 // Option is a subscriber option used to customize the consumer.
 type Option func(s Subscriber)
 
-// WithName set consumer name.
+// WithName set consumer name, used as prefix of the consumer tags.
 func WithName(name string) Option {
 	return func(s Subscriber) {
 		s.SetName(name)
 	}
 }
 
+// WithAutoAck makes the server acknowledge the messages as soon as they are delivered, so handlers must not ack, nack
+// or reject them. Messages being handled are lost if the consumer fails.
+func WithAutoAck() Option {
+	return func(s Subscriber) {
+		s.SetAutoAck(true)
+	}
+}
+
+// WithExclusiveConsumer makes the consumer the only one allowed to consume from the queue.
+func WithExclusiveConsumer() Option {
+	return func(s Subscriber) {
+		s.SetExclusive(true)
+	}
+}
+
+// WithNoLocal asks the server to not deliver messages published by the same connection (not supported by RabbitMQ).
+func WithNoLocal() Option {
+	return func(s Subscriber) {
+		s.SetNoLocal(true)
+	}
+}
+
+// WithNoWait consumes without waiting for the server to confirm the consumer registration.
+func WithNoWait() Option {
+	return func(s Subscriber) {
+		s.SetNoWait(true)
+	}
+}
+
+// WithConsumerPriority set the consumer priority, so the server delivers messages to higher priority consumers while
+// they can receive them.
+func WithConsumerPriority(priority int) Option {
+	return func(s Subscriber) {
+		s.SetConsumerArg(consumerPriorityArg, priority)
+	}
+}
+
+// WithSingleActiveConsumer declares a single active consumer queue, where only one consumer at a time receives the
+// messages and the next one takes over when it fails. Useful to process messages in order with standby consumers.
+func WithSingleActiveConsumer() Option {
+	return func(s Subscriber) {
+		s.SetSingleActiveConsumer(true)
+	}
+}
+
 // WithPrefetch set prefetch count, and size.
 func WithPrefetch(count int) Option {
 	return func(s Subscriber) {
@@ -64,3 +118,115 @@ func WithDurableTopicExchange(name, routingKey string) Option {
 		})
 	}
 }
+
+// WithHeadersExchange declare a durable headers exchange and bind it to the consumer queue matching all or any of
+// the given headers.
+func WithHeadersExchange(name string, match HeadersMatch, headers map[string]interface{}) Option {
+	bindArgs := map[string]interface{}{headersMatchArg: match.String()}
+	for key, value := range headers {
+		bindArgs[key] = value
+	}
+
+	return func(s Subscriber) {
+		s.AddExchange(&Exchange{
+			Name:      name,
+			Type:      ExchangeTypeHeaders,
+			IsDurable: true,
+			BindArgs:  bindArgs,
+		})
+	}
+}
+
+// WithTopicBindings declare a durable topic exchange and bind it to the consumer queue once per routing key.
+func WithTopicBindings(exchange string, routingKeys ...string) Option {
+	return func(s Subscriber) {
+		s.AddExchange(&Exchange{
+			Name:        exchange,
+			Type:        ExchangeTypeTopic,
+			IsDurable:   true,
+			RoutingKeys: routingKeys,
+		})
+	}
+}
+
+// WithQuorumQueue defines a named quorum queue for consumer. A zero deliveryLimit or initialGroupSize keeps the
+// server defaults.
+func WithQuorumQueue(name string, deliveryLimit, initialGroupSize int) Option {
+	return func(s Subscriber) {
+		s.SetQueue(&Queue{
+			Name:             name,
+			Durable:          true,
+			Type:             QueueTypeQuorum,
+			DeliveryLimit:    deliveryLimit,
+			InitialGroupSize: initialGroupSize,
+		})
+	}
+}
+
+// WithStreamQueue defines a named stream queue for consumer. A zero maxAge or maxSegmentSize keeps the server
+// defaults. Stream consumers require a prefetch count, which defaults to 100 unless set by WithPrefetch.
+func WithStreamQueue(name string, maxAge time.Duration, maxSegmentSize int64) Option {
+	return func(s Subscriber) {
+		s.SetQueue(&Queue{
+			Name:           name,
+			Durable:        true,
+			Type:           QueueTypeStream,
+			MaxAge:         maxAge,
+			MaxSegmentSize: maxSegmentSize,
+		})
+	}
+}
+
+// WithStreamOffset set the offset a stream consumer starts consuming from. On reconnection the consumer resumes after
+// the last delivered offset instead.
+func WithStreamOffset(offset StreamOffset) Option {
+	return func(s Subscriber) {
+		s.SetConsumerArg(streamOffsetArg, offset.Value())
+	}
+}
+
+// WithConsumerArg set a consumer argument.
+func WithConsumerArg(key string, value interface{}) Option {
+	return func(s Subscriber) {
+		s.SetConsumerArg(key, value)
+	}
+}
+
+// WithConnectionOptions set the connection options (TLS, authentication, heartbeat, etc). When options.URI is empty
+// the subscriber URI is used.
+func WithConnectionOptions(options *connection.Options) Option {
+	return func(s Subscriber) {
+		s.SetConnectionOptions(options)
+	}
+}
+
+// WithMiddleware transforms the consumed messages with the given middlewares (e.g. claimcheck.New), applied in reverse
+// order before decompression, so they mirror the publisher middlewares.
+func WithMiddleware(middlewares ...pubsub.Middleware) Option {
+	return func(s Subscriber) {
+		for _, middleware := range middlewares {
+			s.AddMiddleware(middleware)
+		}
+	}
+}
+
+// WithEncryption decrypts the consumed bodies encrypted by a publisher configured with the same key provider,
+// rejecting the messages which can't be decrypted. See envelope.NewEncryption.
+func WithEncryption(provider envelope.KeyProvider) Option {
+	return WithMiddleware(envelope.NewEncryption(provider, ""))
+}
+
+// WithSigning verifies the consumed messages signature, rejecting the unsigned or tampered ones. The options must be
+// given in the same order as on the publisher (WithEncryption first) and with the same properties. See
+// envelope.NewSigning.
+func WithSigning(signer envelope.Signer, properties ...envelope.Property) Option {
+	return WithMiddleware(envelope.NewSigning(signer, "", properties...))
+}
+
+// WithSchemaRegistry rejects the consumed messages whose body doesn't match the schema registered for their type and
+// version (see schema.Validate) before they reach the handler.
+func WithSchemaRegistry(registry schema.Registry) Option {
+	return func(s Subscriber) {
+		s.SetSchemaRegistry(registry)
+	}
+}
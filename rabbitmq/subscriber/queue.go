@@ -1,6 +1,9 @@
 package subscriber
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/streadway/amqp"
 )
 
@@ -13,6 +16,21 @@ type Queue struct {
 	MaxPriority   uint8
 	RoutingKey    string
 	QueueBindArgs map[string]interface{}
+
+	// Type is the queue type. See QueueType for details.
+	Type QueueType
+
+	// DeliveryLimit is the number of redeliveries before a message is dropped or dead-lettered (quorum queues only).
+	DeliveryLimit int
+
+	// InitialGroupSize is the number of cluster nodes the queue is replicated to (quorum and stream queues only).
+	InitialGroupSize int
+
+	// MaxAge is the retention time of the stream messages, rounded up to seconds (stream queues only).
+	MaxAge time.Duration
+
+	// MaxSegmentSize is the max size in bytes of the stream segment files on disk (stream queues only).
+	MaxSegmentSize int64
 }
 
 func (o *Queue) GetArgs() amqp.Table {
@@ -21,5 +39,33 @@ func (o *Queue) GetArgs() amqp.Table {
 		args["x-max-priority"] = o.MaxPriority
 	}
 
+	if o.Type != QueueTypeClassic {
+		args["x-queue-type"] = o.Type.String()
+	}
+
+	if o.DeliveryLimit > 0 {
+		args["x-delivery-limit"] = o.DeliveryLimit
+	}
+
+	if o.InitialGroupSize > 0 {
+		args[o.getInitialGroupSizeArg()] = o.InitialGroupSize
+	}
+
+	if o.MaxAge > 0 {
+		args["x-max-age"] = fmt.Sprintf("%ds", int64((o.MaxAge+time.Second-1)/time.Second))
+	}
+
+	if o.MaxSegmentSize > 0 {
+		args["x-stream-max-segment-size-bytes"] = o.MaxSegmentSize
+	}
+
 	return args
 }
+
+func (o *Queue) getInitialGroupSizeArg() string {
+	if o.Type == QueueTypeStream {
+		return "x-initial-cluster-size"
+	}
+
+	return "x-quorum-initial-group-size"
+}
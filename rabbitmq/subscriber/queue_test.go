@@ -0,0 +1,48 @@
+package subscriber
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestQueueGetArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		queue Queue
+		want  amqp.Table
+	}{
+		{name: "classic", queue: Queue{}, want: amqp.Table{}},
+		{
+			name:  "quorum",
+			queue: Queue{Type: QueueTypeQuorum, DeliveryLimit: 5, InitialGroupSize: 3},
+			want:  amqp.Table{"x-queue-type": "quorum", "x-delivery-limit": 5, "x-quorum-initial-group-size": 3},
+		},
+		{
+			name:  "stream",
+			queue: Queue{Type: QueueTypeStream, InitialGroupSize: 3, MaxAge: 90 * time.Minute, MaxSegmentSize: 1024},
+			want: amqp.Table{"x-queue-type": "stream", "x-initial-cluster-size": 3, "x-max-age": "5400s",
+				"x-stream-max-segment-size-bytes": int64(1024)},
+		},
+		{
+			name:  "stream sub-second max age",
+			queue: Queue{Type: QueueTypeStream, MaxAge: time.Millisecond},
+			want:  amqp.Table{"x-queue-type": "stream", "x-max-age": "1s"},
+		},
+		{
+			name:  "stream fractional max age",
+			queue: Queue{Type: QueueTypeStream, MaxAge: 1500 * time.Millisecond},
+			want:  amqp.Table{"x-queue-type": "stream", "x-max-age": "2s"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.queue.GetArgs(); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("GetArgs() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package subscriber
+
+const (
+	/*
+		QueueTypeClassic represents a classic queue type.
+
+		This queue type is the default and is the only one supporting exclusive, auto-deleted and priority queues.
+	*/
+	QueueTypeClassic QueueType = iota
+
+	/*
+		QueueTypeQuorum represents a quorum queue type.
+
+		Quorum queues are durable, replicated queues based on the Raft consensus algorithm, recommended when data
+		safety is a priority. Requires RabbitMQ 3.8 or later.
+	*/
+	QueueTypeQuorum
+
+	/*
+		QueueTypeStream represents a stream queue type.
+
+		Streams are durable, replicated and append-only logs which are not drained by consumers, so messages can be
+		consumed many times starting from any offset (see StreamOffset). Requires RabbitMQ 3.9 or later and a
+		consumer prefetch count.
+	*/
+	QueueTypeStream
+)
+
+var mapQueueTypeString = map[QueueType]string{
+	QueueTypeClassic: "classic",
+	QueueTypeQuorum:  "quorum",
+	QueueTypeStream:  "stream",
+}
+
+// QueueType represents the RabbitMQ queue type ("x-queue-type" argument).
+type QueueType uint8
+
+/*
+	String returns the QueueType as string:
+		QueueTypeClassic => "classic"
+		QueueTypeQuorum  => "quorum"
+		QueueTypeStream  => "stream"
+*/
+func (t QueueType) String() string {
+	return mapQueueTypeString[t]
+}
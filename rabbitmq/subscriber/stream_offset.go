@@ -0,0 +1,90 @@
+package subscriber
+
+import (
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+const (
+	streamOffsetArg = "x-stream-offset"
+
+	// defaultStreamPrefetchCount is the prefetch count of stream consumers without one, since the broker refuses
+	// stream consumers with an unlimited prefetch.
+	defaultStreamPrefetchCount = 100
+)
+
+// StreamOffset defines where a stream consumer starts consuming from ("x-stream-offset" consumer argument).
+type StreamOffset struct {
+	value interface{}
+}
+
+// StreamOffsetFirst starts consuming from the first available message of the stream.
+func StreamOffsetFirst() StreamOffset {
+	return StreamOffset{value: "first"}
+}
+
+// StreamOffsetLast starts consuming from the last written chunk of messages of the stream.
+func StreamOffsetLast() StreamOffset {
+	return StreamOffset{value: "last"}
+}
+
+// StreamOffsetNext starts consuming only the messages published after the consumer starts (default).
+func StreamOffsetNext() StreamOffset {
+	return StreamOffset{value: "next"}
+}
+
+// StreamOffsetTimestamp starts consuming from the messages published at or after the given time.
+func StreamOffsetTimestamp(timestamp time.Time) StreamOffset {
+	return StreamOffset{value: timestamp}
+}
+
+// StreamOffsetAt starts consuming from the given numerical offset of the stream.
+func StreamOffsetAt(offset int64) StreamOffset {
+	return StreamOffset{value: offset}
+}
+
+// Value returns the offset as expected by the "x-stream-offset" consumer argument.
+func (o StreamOffset) Value() interface{} {
+	return o.value
+}
+
+// trackStreamOffset records the offset of a stream delivery ("x-stream-offset" header), so the consumer resumes after
+// it on reconnection instead of consuming again from the configured offset.
+func (s *subscriber) trackStreamOffset(delivery amqp.Delivery) {
+	offset, isStream := delivery.Headers[streamOffsetArg].(int64)
+	if !isStream {
+		return
+	}
+
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
+	s.lastStreamOffset = &offset
+}
+
+// getConsumerArgs returns the consumer arguments, starting stream consumers after the last delivered offset.
+func (s *subscriber) getConsumerArgs() map[string]interface{} {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+
+	if s.lastStreamOffset == nil {
+		return s.consumerArgs
+	}
+
+	args := make(map[string]interface{}, len(s.consumerArgs)+1)
+	for key, value := range s.consumerArgs {
+		args[key] = value
+	}
+
+	args[streamOffsetArg] = *s.lastStreamOffset + 1
+	return args
+}
+
+func (s *subscriber) getPrefetchCount() int {
+	if s.prefetchQos.Count == 0 && s.queue.Type == QueueTypeStream {
+		return defaultStreamPrefetchCount
+	}
+
+	return s.prefetchQos.Count
+}
@@ -1,20 +1,48 @@
 package subscriber
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
 	"github.com/maykonlf/pubsub"
-	"github.com/maykonlf/pubsub/rabbitmq"
 	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/maykonlf/pubsub/schema"
 	"github.com/streadway/amqp"
 )
 
 type Subscriber interface {
 	pubsub.Subscriber
+	SubscribeBatch(ctx context.Context, maxSize int, maxWait time.Duration, handler BatchHandler) error
 	AddExchange(exchange *Exchange)
+	AddBinding(exchange, routingKey string) error
+	RemoveBinding(exchange, routingKey string) error
 	SetQueue(queue *Queue)
 	SetName(name string)
+	SetAutoAck(isAutoAck bool)
+	SetExclusive(isExclusive bool)
+	SetNoLocal(isNoLocal bool)
+	SetNoWait(noWait bool)
+	SetSingleActiveConsumer(isSingleActiveConsumer bool)
 	SetPrefetchQos(qos *PrefetchQos)
+	SetConsumerArg(key string, value interface{})
+	SetConnectionOptions(options *connection.Options)
+	AddMiddleware(middleware pubsub.Middleware)
+	SetSchemaRegistry(registry schema.Registry)
 }
 
+const (
+	reconnectPollInterval   = 100 * time.Millisecond
+	consumerPriorityArg     = "x-priority"
+	singleActiveConsumerArg = "x-single-active-consumer"
+)
+
+// ErrUnknownExchange is returned when binding to an exchange which was not added to the subscriber.
+var ErrUnknownExchange = errors.New("subscriber: unknown exchange")
+
 // NewSubscriber creates a new RabbitMQ consumer.
 func NewSubscriber(uri string, options ...Option) Subscriber {
 	subscriber := &subscriber{
@@ -24,6 +52,9 @@ func NewSubscriber(uri string, options ...Option) Subscriber {
 		queue:                     &Queue{},
 		prefetchQos:               &PrefetchQos{},
 		connectionOptions:         &connection.Options{URI: uri},
+		bindingsMutex:             &sync.Mutex{},
+		stateMutex:                &sync.RWMutex{},
+		deliveryChannelMutex:      &sync.RWMutex{},
 	}
 
 	for _, optionFunction := range options {
@@ -33,29 +64,52 @@ func NewSubscriber(uri string, options ...Option) Subscriber {
 	return subscriber
 }
 
+type binding struct {
+	exchange   *Exchange
+	routingKey string
+}
+
 type subscriber struct {
 	active                    bool
 	disconnectionErrorChannel chan error
 	subscriberHandler         func(message pubsub.Message)
 	messageDeliveryChannel    <-chan amqp.Delivery
+	deliveryChannelMutex      *sync.RWMutex
+	stateMutex                *sync.RWMutex
 	conn                      connection.Connection
 	connectionOptions         *connection.Options
 	exchanges                 []*Exchange
+	removedBindings           []binding
+	bindingsMutex             *sync.Mutex
 	queue                     *Queue
 	isAutoAck                 bool
 	name                      string
+	consumerTag               string
+	consumeCount              int
+	isSingleActiveConsumer    bool
 	isExclusive               bool
 	isNoLocal                 bool
 	noWaitForRabbitResponse   bool
 	consumerArgs              map[string]interface{}
+	lastStreamOffset          *int64
 	prefetchQos               *PrefetchQos
+	middlewares               []pubsub.Middleware
+	schemaRegistry            schema.Registry
 }
 
-// Subscribe start consuming and delivery every consumed message to the given function.
+/*
+	Subscribe start consuming and delivery every consumed message to the given function. It panics when the connection
+	gives up reconnecting.
+
+	Messages are transformed by the middlewares (see WithMiddleware) and their compressed bodies decompressed according
+	to the content encoding (see the compression package) before being delivered. Messages which fail to decompress, are
+	unprocessable by a middleware or don't match their schema (see WithSchemaRegistry) are rejected without requeue, and
+	other failures requeue them.
+*/
 func (s *subscriber) Subscribe(handler func(message pubsub.Message)) {
 	s.registerSubscriberHandler(handler)
-	s.setupSubscriber()
-	s.openConsumerChannel()
+	panicOnError(s.setupSubscriber())
+	panicOnError(s.openConsumerChannel())
 	s.startSubscriber()
 }
 
@@ -63,76 +117,170 @@ func (s *subscriber) registerSubscriberHandler(handler func(message pubsub.Messa
 	s.subscriberHandler = handler
 }
 
-func (s *subscriber) setupSubscriber() {
-	s.setupChannelQos()
-	s.setupExchange()
-	s.setupQueue()
-	s.bindQueueToExchange()
+func (s *subscriber) setupSubscriber() error {
+	if err := s.setupChannelQos(); err != nil {
+		return err
+	}
+
+	if err := s.setupExchange(); err != nil {
+		return err
+	}
+
+	if err := s.setupQueue(); err != nil {
+		return err
+	}
+
+	return s.bindQueueToExchange()
 }
 
-func (s *subscriber) openConsumerChannel() {
+func (s *subscriber) openConsumerChannel() error {
+	consumerTag := s.nextConsumerTag()
 	delivery, err := s.getConnection().GetChannel().Consume(
-		s.queue.Name,
-		s.name,
+		s.getQueueName(),
+		consumerTag,
 		s.isAutoAck,
 		s.isExclusive,
 		s.isNoLocal,
 		s.noWaitForRabbitResponse,
-		s.consumerArgs)
+		s.getConsumerArgs())
 	if err != nil {
-		panic(err)
+		return err
 	}
 
+	s.deliveryChannelMutex.Lock()
+	defer s.deliveryChannelMutex.Unlock()
+
 	s.messageDeliveryChannel = delivery
+	return nil
+}
+
+func (s *subscriber) getDeliveryChannel() <-chan amqp.Delivery {
+	s.deliveryChannelMutex.RLock()
+	defer s.deliveryChannelMutex.RUnlock()
+
+	return s.messageDeliveryChannel
 }
 
 func (s *subscriber) startSubscriber() {
 	s.getConnection().SetReconnectHooks(s.reconnectSubscriber)
 	for {
 		s.handleConsume()
+		panicOnError(s.waitForReconnection(context.Background()))
 	}
 }
 
-func (s *subscriber) setupChannelQos() {
-	err := s.getConnection().GetChannel().Qos(s.prefetchQos.Count, s.prefetchQos.Size, s.prefetchQos.IsGlobal)
-	if err != nil {
-		panic(err)
-	}
+func (s *subscriber) setupChannelQos() error {
+	return s.getConnection().GetChannel().Qos(s.getPrefetchCount(), s.prefetchQos.Size, s.prefetchQos.IsGlobal)
 }
 
-func (s *subscriber) setupQueue() {
+func (s *subscriber) setupQueue() error {
 	queue, err := s.getConnection().GetChannel().QueueDeclare(
-		s.queue.Name,
+		s.getQueueName(),
 		s.queue.Durable,
 		s.queue.AutoDelete,
 		s.queue.Exclusive,
 		s.queue.NoWait,
-		s.queue.GetArgs())
+		s.getQueueArgs())
 	if err != nil {
-		panic(err)
+		return err
 	}
 
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
 	s.queue.Name = queue.Name
+	return nil
 }
 
-func (s *subscriber) reconnectSubscriber() {
-	s.setupSubscriber()
-	s.openConsumerChannel()
+func (s *subscriber) getQueueName() string {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+
+	return s.queue.Name
+}
+
+func (s *subscriber) getQueueArgs() map[string]interface{} {
+	args := s.queue.GetArgs()
+	if s.isSingleActiveConsumer {
+		args[singleActiveConsumerArg] = true
+	}
+
+	return args
+}
+
+// nextConsumerTag returns a new consumer tag for each consume, prefixed by the subscriber name (or by the host name
+// and process id when no name is set), so tags are unique per channel and recognizable in the management UI.
+func (s *subscriber) nextConsumerTag() string {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
+	s.consumeCount++
+	s.consumerTag = fmt.Sprintf("%s-%d", s.getConsumerTagPrefix(), s.consumeCount)
+	return s.consumerTag
+}
+
+func (s *subscriber) getConsumerTag() string {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+
+	return s.consumerTag
+}
+
+func (s *subscriber) getConsumerTagPrefix() string {
+	if s.name != "" {
+		return s.name
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "consumer"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func (s *subscriber) reconnectSubscriber() error {
+	if err := s.setupSubscriber(); err != nil {
+		return err
+	}
+
+	return s.openConsumerChannel()
 }
 
 func (s *subscriber) handleConsume() {
-	for delivery := range s.messageDeliveryChannel {
-		message := rabbitmq.NewMessageFromDelivery(delivery)
+	for delivery := range s.getDeliveryChannel() {
+		s.trackStreamOffset(delivery)
+		message, err := s.newMessage(context.Background(), delivery)
+		if err != nil {
+			s.settleFailedDelivery(delivery, err)
+			continue
+		}
+
 		go s.handleDelivery(message)
 	}
 }
 
+func (s *subscriber) waitForReconnection(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.getConnection().Done():
+		return s.getConnection().Err()
+	case <-time.After(reconnectPollInterval):
+		return nil
+	}
+}
+
 func (s *subscriber) handleDelivery(message pubsub.Message) {
 	s.subscriberHandler(message)
 }
 
-func (s *subscriber) setupExchange() {
-	for _, exchange := range s.exchanges {
+func (s *subscriber) setupExchange() error {
+	s.bindingsMutex.Lock()
+	exchanges := append([]*Exchange(nil), s.exchanges...)
+	s.bindingsMutex.Unlock()
+
+	for _, exchange := range exchanges {
 		err := s.getConnection().GetChannel().ExchangeDeclare(
 			exchange.Name,
 			exchange.Type.String(),
@@ -142,29 +290,125 @@ func (s *subscriber) setupExchange() {
 			exchange.NoWait,
 			exchange.Args)
 		if err != nil {
-			panic(err)
+			return err
 		}
 	}
+
+	return nil
 }
 
-func (s *subscriber) bindQueueToExchange() {
+func (s *subscriber) bindQueueToExchange() error {
+	s.bindingsMutex.Lock()
+	defer s.bindingsMutex.Unlock()
+
+	for _, removed := range s.removedBindings {
+		if err := s.unbind(removed.exchange, removed.routingKey); err != nil {
+			return err
+		}
+	}
+
+	s.removedBindings = nil
 	for _, exchange := range s.exchanges {
-		err := s.conn.GetChannel().QueueBind(
-			s.queue.Name,
-			exchange.RoutingKey,
-			exchange.Name,
-			s.queue.NoWait,
-			s.queue.QueueBindArgs)
-		if err != nil {
-			panic(err)
+		for _, routingKey := range exchange.RoutingKeys {
+			if err := s.bind(exchange, routingKey); err != nil {
+				return err
+			}
 		}
 	}
+
+	return nil
+}
+
+func (s *subscriber) bind(exchange *Exchange, routingKey string) error {
+	return s.getConnection().GetChannel().QueueBind(
+		s.getQueueName(),
+		routingKey,
+		exchange.Name,
+		s.queue.NoWait,
+		exchange.getBindArgs(s.queue.QueueBindArgs))
+}
+
+func (s *subscriber) unbind(exchange *Exchange, routingKey string) error {
+	return s.getConnection().GetChannel().QueueUnbind(
+		s.getQueueName(),
+		routingKey,
+		exchange.Name,
+		exchange.getBindArgs(s.queue.QueueBindArgs))
 }
 
 func (s *subscriber) AddExchange(exchange *Exchange) {
+	s.bindingsMutex.Lock()
+	defer s.bindingsMutex.Unlock()
+
+	exchange.normalizeRoutingKeys()
 	s.exchanges = append(s.exchanges, exchange)
 }
 
+// AddBinding binds the consumer queue to a previously added exchange with one more routing key. A live subscriber is
+// bound immediately, and the binding is redeclared on every reconnection.
+func (s *subscriber) AddBinding(exchangeName, routingKey string) error {
+	s.bindingsMutex.Lock()
+	defer s.bindingsMutex.Unlock()
+
+	exchange := s.findExchange(exchangeName)
+	if exchange == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownExchange, exchangeName)
+	}
+
+	if !exchange.addRoutingKey(routingKey) {
+		return nil
+	}
+
+	s.removeFromRemovedBindings(exchange, routingKey)
+	if !s.isConnected() {
+		return nil
+	}
+
+	return s.bind(exchange, routingKey)
+}
+
+// RemoveBinding unbinds a routing key of a previously added exchange from the consumer queue. A live subscriber is
+// unbound immediately, and when the unbind fails (e.g. while disconnected) it is retried on the next reconnection.
+func (s *subscriber) RemoveBinding(exchangeName, routingKey string) error {
+	s.bindingsMutex.Lock()
+	defer s.bindingsMutex.Unlock()
+
+	exchange := s.findExchange(exchangeName)
+	if exchange == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownExchange, exchangeName)
+	}
+
+	if !exchange.removeRoutingKey(routingKey) || !s.isConnected() {
+		return nil
+	}
+
+	if err := s.unbind(exchange, routingKey); err != nil {
+		s.removedBindings = append(s.removedBindings, binding{exchange: exchange, routingKey: routingKey})
+		return err
+	}
+
+	return nil
+}
+
+func (s *subscriber) findExchange(name string) *Exchange {
+	for _, exchange := range s.exchanges {
+		if exchange.Name == name {
+			return exchange
+		}
+	}
+
+	return nil
+}
+
+func (s *subscriber) removeFromRemovedBindings(exchange *Exchange, routingKey string) {
+	for i, removed := range s.removedBindings {
+		if removed.exchange == exchange && removed.routingKey == routingKey {
+			s.removedBindings = append(s.removedBindings[:i:i], s.removedBindings[i+1:]...)
+			return
+		}
+	}
+}
+
 func (s *subscriber) SetQueue(queue *Queue) {
 	s.queue = queue
 }
@@ -173,14 +417,69 @@ func (s *subscriber) SetName(name string) {
 	s.name = name
 }
 
+func (s *subscriber) SetAutoAck(isAutoAck bool) {
+	s.isAutoAck = isAutoAck
+}
+
+func (s *subscriber) SetExclusive(isExclusive bool) {
+	s.isExclusive = isExclusive
+}
+
+func (s *subscriber) SetNoLocal(isNoLocal bool) {
+	s.isNoLocal = isNoLocal
+}
+
+func (s *subscriber) SetNoWait(noWait bool) {
+	s.noWaitForRabbitResponse = noWait
+}
+
+func (s *subscriber) SetSingleActiveConsumer(isSingleActiveConsumer bool) {
+	s.isSingleActiveConsumer = isSingleActiveConsumer
+}
+
 func (s *subscriber) SetPrefetchQos(qos *PrefetchQos) {
 	s.prefetchQos = qos
 }
 
+func (s *subscriber) SetConsumerArg(key string, value interface{}) {
+	if s.consumerArgs == nil {
+		s.consumerArgs = map[string]interface{}{}
+	}
+
+	s.consumerArgs[key] = value
+}
+
+func (s *subscriber) SetConnectionOptions(options *connection.Options) {
+	if options.URI == "" {
+		options.URI = s.connectionOptions.URI
+	}
+
+	s.connectionOptions = options
+}
+
+// getConnection returns the subscriber connection, connecting on the first call. The connection and the state
+// changed by the reconnect hooks (queue name and consumer tag) are guarded by stateMutex, since bindings may be
+// changed concurrently with reconnections.
 func (s *subscriber) getConnection() connection.Connection {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
 	if s.conn == nil {
 		s.conn = connection.NewConnection(s.connectionOptions)
 	}
 
 	return s.conn
 }
+
+func (s *subscriber) isConnected() bool {
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+
+	return s.conn != nil
+}
+
+func panicOnError(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,136 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/maykonlf/pubsub/rabbitmq/connection"
+	"github.com/streadway/amqp"
+)
+
+const (
+	// ResourceExchange identifies an exchange in a Drift report.
+	ResourceExchange = "exchange"
+
+	// ResourceQueue identifies a queue in a Drift report.
+	ResourceQueue = "queue"
+)
+
+// Drift reports a declared resource which is missing on the broker.
+type Drift struct {
+	// Resource is the resource kind (ResourceExchange or ResourceQueue).
+	Resource string
+
+	// Name is the resource name.
+	Name string
+}
+
+// String returns a human readable drift description.
+func (d Drift) String() string {
+	return fmt.Sprintf("%s %q is missing", d.Resource, d.Name)
+}
+
+// Apply declares all exchanges, queues and bindings of the topology. Declarations are idempotent so Apply can be
+// called on every (re)connection, but it fails if a resource already exists with different settings, or with an
+// ErrInvalidMaxPriority wrapped error if a queue max priority is out of range.
+func (t *Topology) Apply(conn connection.Connection) error {
+	channel := conn.GetChannel()
+
+	for _, exchange := range t.Exchanges {
+		err := channel.ExchangeDeclare(exchange.Name, exchange.kind(), exchange.Durable, exchange.AutoDelete,
+			exchange.Internal, exchange.NoWait, exchange.getArgs())
+		if err != nil {
+			return fmt.Errorf("topology: declare exchange %q: %w", exchange.Name, err)
+		}
+	}
+
+	for _, queue := range t.Queues {
+		if _, err := queue.MaxPriority(); err != nil {
+			return err
+		}
+
+		_, err := channel.QueueDeclare(queue.Name, queue.Durable, queue.AutoDelete, queue.Exclusive, queue.NoWait,
+			queue.Args)
+		if err != nil {
+			return fmt.Errorf("topology: declare queue %q: %w", queue.Name, err)
+		}
+	}
+
+	for _, binding := range t.ExchangeBindings {
+		err := channel.ExchangeBind(binding.Destination, binding.RoutingKey, binding.Source, binding.NoWait,
+			binding.Args)
+		if err != nil {
+			return fmt.Errorf("topology: bind exchange %q to %q: %w", binding.Destination, binding.Source, err)
+		}
+	}
+
+	for _, binding := range t.Bindings {
+		err := channel.QueueBind(binding.Queue, binding.RoutingKey, binding.Exchange, binding.NoWait, binding.Args)
+		if err != nil {
+			return fmt.Errorf("topology: bind queue %q to %q: %w", binding.Queue, binding.Exchange, err)
+		}
+	}
+
+	return nil
+}
+
+/*
+	Diff checks, using passive declarations, which exchanges and queues of the topology are missing on the broker.
+
+	Each check runs on its own short-lived channel since the broker closes the channel of a failed passive declaration.
+	AMQP has no way to inspect bindings or declaration arguments, so only the resources existence is compared.
+*/
+func (t *Topology) Diff(conn connection.Connection) ([]Drift, error) {
+	var drifts []Drift
+
+	for _, exchange := range t.Exchanges {
+		exists, err := checkPassive(conn, func(channel *amqp.Channel) error {
+			return channel.ExchangeDeclarePassive(exchange.Name, exchange.kind(), exchange.Durable,
+				exchange.AutoDelete, exchange.Internal, false, exchange.getArgs())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("topology: check exchange %q: %w", exchange.Name, err)
+		}
+
+		if !exists {
+			drifts = append(drifts, Drift{Resource: ResourceExchange, Name: exchange.Name})
+		}
+	}
+
+	for _, queue := range t.Queues {
+		exists, err := checkPassive(conn, func(channel *amqp.Channel) error {
+			_, err := channel.QueueDeclarePassive(queue.Name, queue.Durable, queue.AutoDelete, queue.Exclusive,
+				false, queue.Args)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("topology: check queue %q: %w", queue.Name, err)
+		}
+
+		if !exists {
+			drifts = append(drifts, Drift{Resource: ResourceQueue, Name: queue.Name})
+		}
+	}
+
+	return drifts, nil
+}
+
+func checkPassive(conn connection.Connection, declare func(channel *amqp.Channel) error) (bool, error) {
+	channel, err := conn.GetConn().Channel()
+	if err != nil {
+		return false, err
+	}
+
+	err = declare(channel)
+	if isNotFound(err) {
+		return false, nil
+	}
+
+	_ = channel.Close()
+	return err == nil, err
+}
+
+func isNotFound(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound
+}
@@ -0,0 +1,80 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+)
+
+const maxPriorityArg = "x-max-priority"
+
+// ErrInvalidMaxPriority is returned when a queue "x-max-priority" argument is not an integer between 1 and 255.
+var ErrInvalidMaxPriority = errors.New("topology: invalid queue max priority")
+
+// MaxPriority returns the queue max priority ("x-max-priority" argument), or 0 when it is not a priority queue.
+func (q *Queue) MaxPriority() (uint8, error) {
+	value, found := q.Args[maxPriorityArg]
+	if !found {
+		return 0, nil
+	}
+
+	var maxPriority int64
+	switch v := value.(type) {
+	case uint8:
+		maxPriority = int64(v)
+	case int:
+		maxPriority = int64(v)
+	case int16:
+		maxPriority = int64(v)
+	case int32:
+		maxPriority = int64(v)
+	case int64:
+		maxPriority = v
+	default:
+		return 0, fmt.Errorf("%w: queue %q: %v", ErrInvalidMaxPriority, q.Name, value)
+	}
+
+	if maxPriority < 1 || maxPriority > 255 {
+		return 0, fmt.Errorf("%w: queue %q: %d", ErrInvalidMaxPriority, q.Name, maxPriority)
+	}
+
+	return uint8(maxPriority), nil
+}
+
+// QueueMaxPriority returns the max priority of the named queue, and false when it is not a declared priority queue.
+func (t *Topology) QueueMaxPriority(name string) (uint8, bool) {
+	for i := range t.Queues {
+		if t.Queues[i].Name != name {
+			continue
+		}
+
+		maxPriority, err := t.Queues[i].MaxPriority()
+		return maxPriority, err == nil && maxPriority != 0
+	}
+
+	return 0, false
+}
+
+// MaxPriority returns the lowest max priority of the priority queues bound to the exchange, so priorities within it
+// are honored by all of them, and false when no priority queue is bound to it.
+func (t *Topology) MaxPriority(exchange string) (uint8, bool) {
+	var lowest uint8
+	var found bool
+	for _, binding := range t.Bindings {
+		if binding.Exchange != exchange {
+			continue
+		}
+
+		for i := range t.Queues {
+			maxPriority, err := t.Queues[i].MaxPriority()
+			if t.Queues[i].Name != binding.Queue || err != nil || maxPriority == 0 {
+				continue
+			}
+
+			if !found || maxPriority < lowest {
+				lowest, found = maxPriority, true
+			}
+		}
+	}
+
+	return lowest, found
+}
@@ -0,0 +1,220 @@
+package topology
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const alternateExchangeArg = "alternate-exchange"
+
+// Topology describes the RabbitMQ exchanges, queues and bindings an application depends on.
+type Topology struct {
+	// Exchanges are the exchanges to be declared.
+	Exchanges []Exchange `json:"exchanges" yaml:"exchanges"`
+
+	// Queues are the queues to be declared.
+	Queues []Queue `json:"queues" yaml:"queues"`
+
+	// Bindings are the queue to exchange bindings.
+	Bindings []Binding `json:"bindings" yaml:"bindings"`
+
+	// ExchangeBindings are the exchange to exchange bindings.
+	ExchangeBindings []ExchangeBinding `json:"exchangeBindings" yaml:"exchangeBindings"`
+}
+
+// Exchange describes an exchange declaration.
+type Exchange struct {
+	// Name is the exchange name.
+	Name string `json:"name" yaml:"name"`
+
+	// Type is the exchange type: "direct" (default), "fanout", "topic" or "headers".
+	Type string `json:"type" yaml:"type"`
+
+	// Durable defines if the exchange survives broker restarts.
+	Durable bool `json:"durable" yaml:"durable"`
+
+	// AutoDelete defines if the exchange is deleted when its last binding is removed.
+	AutoDelete bool `json:"autoDelete" yaml:"autoDelete"`
+
+	// Internal defines if the exchange only accepts publishings from other exchanges.
+	Internal bool `json:"internal" yaml:"internal"`
+
+	// NoWait defines if the declaration does not wait for the server confirmation.
+	NoWait bool `json:"noWait" yaml:"noWait"`
+
+	// Args are the exchange declaration arguments.
+	Args map[string]interface{} `json:"args" yaml:"args"`
+
+	// AlternateExchange is the exchange receiving the messages which can't be routed ("alternate-exchange" argument).
+	AlternateExchange string `json:"alternateExchange" yaml:"alternateExchange"`
+}
+
+// Queue describes a queue declaration.
+type Queue struct {
+	// Name is the queue name.
+	Name string `json:"name" yaml:"name"`
+
+	// Durable defines if the queue survives broker restarts.
+	Durable bool `json:"durable" yaml:"durable"`
+
+	// AutoDelete defines if the queue is deleted when its last consumer is cancelled.
+	AutoDelete bool `json:"autoDelete" yaml:"autoDelete"`
+
+	// Exclusive defines if the queue is only accessible by the declaring connection.
+	Exclusive bool `json:"exclusive" yaml:"exclusive"`
+
+	// NoWait defines if the declaration does not wait for the server confirmation.
+	NoWait bool `json:"noWait" yaml:"noWait"`
+
+	// Args are the queue declaration arguments (e.g. "x-max-priority", "x-queue-type").
+	Args map[string]interface{} `json:"args" yaml:"args"`
+}
+
+// Binding describes a queue to exchange binding.
+type Binding struct {
+	// Queue is the bound queue name.
+	Queue string `json:"queue" yaml:"queue"`
+
+	// Exchange is the source exchange name.
+	Exchange string `json:"exchange" yaml:"exchange"`
+
+	// RoutingKey is the binding routing key.
+	RoutingKey string `json:"routingKey" yaml:"routingKey"`
+
+	// NoWait defines if the binding does not wait for the server confirmation.
+	NoWait bool `json:"noWait" yaml:"noWait"`
+
+	// Args are the binding arguments (e.g. "x-match" for headers exchanges).
+	Args map[string]interface{} `json:"args" yaml:"args"`
+}
+
+// ExchangeBinding describes an exchange to exchange binding.
+type ExchangeBinding struct {
+	// Destination is the exchange receiving the messages.
+	Destination string `json:"destination" yaml:"destination"`
+
+	// Source is the exchange forwarding the messages.
+	Source string `json:"source" yaml:"source"`
+
+	// RoutingKey is the binding routing key.
+	RoutingKey string `json:"routingKey" yaml:"routingKey"`
+
+	// NoWait defines if the binding does not wait for the server confirmation.
+	NoWait bool `json:"noWait" yaml:"noWait"`
+
+	// Args are the binding arguments.
+	Args map[string]interface{} `json:"args" yaml:"args"`
+}
+
+// FromJSON parses a JSON topology definition.
+func FromJSON(data []byte) (*Topology, error) {
+	t := &Topology{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("topology: parse json: %w", err)
+	}
+
+	t.normalizeArgs()
+	return t, nil
+}
+
+// FromYAML parses a YAML topology definition.
+func FromYAML(data []byte) (*Topology, error) {
+	t := &Topology{}
+	if err := yaml.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("topology: parse yaml: %w", err)
+	}
+
+	t.normalizeArgs()
+	return t, nil
+}
+
+// LoadFile reads a topology definition from a ".json", ".yaml" or ".yml" file.
+func LoadFile(path string) (*Topology, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("topology: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FromJSON(data)
+	case ".yaml", ".yml":
+		return FromYAML(data)
+	default:
+		return nil, fmt.Errorf("topology: unsupported file extension %q", filepath.Ext(path))
+	}
+}
+
+func (t *Topology) normalizeArgs() {
+	for i := range t.Exchanges {
+		t.Exchanges[i].Args = normalizeTable(t.Exchanges[i].Args)
+	}
+
+	for i := range t.Queues {
+		t.Queues[i].Args = normalizeTable(t.Queues[i].Args)
+	}
+
+	for i := range t.Bindings {
+		t.Bindings[i].Args = normalizeTable(t.Bindings[i].Args)
+	}
+
+	for i := range t.ExchangeBindings {
+		t.ExchangeBindings[i].Args = normalizeTable(t.ExchangeBindings[i].Args)
+	}
+}
+
+// normalizeTable converts decoded values to types accepted by AMQP tables: whole JSON numbers become int64 (so
+// arguments like "x-max-priority" are not sent as floats) and nested lists and maps are normalized recursively.
+func normalizeTable(table map[string]interface{}) map[string]interface{} {
+	for key, value := range table {
+		table[key] = normalizeValue(value)
+	}
+
+	return table
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case int:
+		return int64(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeValue(v[i])
+		}
+	case map[string]interface{}:
+		return normalizeTable(v)
+	}
+
+	return value
+}
+
+func (e *Exchange) getArgs() map[string]interface{} {
+	if e.AlternateExchange == "" {
+		return e.Args
+	}
+
+	args := make(map[string]interface{}, len(e.Args)+1)
+	for key, value := range e.Args {
+		args[key] = value
+	}
+
+	args[alternateExchangeArg] = e.AlternateExchange
+	return args
+}
+
+func (e *Exchange) kind() string {
+	if e.Type == "" {
+		return "direct"
+	}
+
+	return e.Type
+}
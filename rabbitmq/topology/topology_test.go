@@ -0,0 +1,107 @@
+package topology
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  interface{}
+	}{
+		{name: "yaml int", value: 10, want: int64(10)},
+		{name: "json whole float", value: float64(60000), want: int64(60000)},
+		{name: "json fractional float", value: 1.5, want: 1.5},
+		{name: "int64", value: int64(5), want: int64(5)},
+		{name: "string", value: "quorum", want: "quorum"},
+		{name: "bool", value: true, want: true},
+		{name: "nil", value: nil, want: nil},
+		{name: "list", value: []interface{}{float64(1), "value", 2}, want: []interface{}{int64(1), "value", int64(2)}},
+		{
+			name:  "nested map",
+			value: map[string]interface{}{"x-max-priority": float64(10), "nested": map[string]interface{}{"ttl": 1}},
+			want: map[string]interface{}{"x-max-priority": int64(10),
+				"nested": map[string]interface{}{"ttl": int64(1)}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := normalizeValue(test.value); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("normalizeValue(%#v) = %#v, want %#v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	const jsonDefinition = `{
+		"exchanges": [{"name": "orders", "type": "topic", "durable": true, "alternateExchange": "unrouted"}],
+		"queues": [{"name": "order.created", "durable": true,
+			"args": {"x-max-priority": 10, "x-message-ttl": 60000, "x-queue-type": "classic"}}],
+		"bindings": [{"queue": "order.created", "exchange": "orders", "routingKey": "order.created"}],
+		"exchangeBindings": [{"destination": "orders", "source": "legacy", "routingKey": "#"}]
+	}`
+
+	const yamlDefinition = `
+exchanges:
+  - name: orders
+    type: topic
+    durable: true
+    alternateExchange: unrouted
+queues:
+  - name: order.created
+    durable: true
+    args:
+      x-max-priority: 10
+      x-message-ttl: 60000
+      x-queue-type: classic
+bindings:
+  - queue: order.created
+    exchange: orders
+    routingKey: order.created
+exchangeBindings:
+  - destination: orders
+    source: legacy
+    routingKey: "#"
+`
+
+	want := &Topology{
+		Exchanges: []Exchange{{Name: "orders", Type: "topic", Durable: true, AlternateExchange: "unrouted"}},
+		Queues: []Queue{{
+			Name:    "order.created",
+			Durable: true,
+			Args: map[string]interface{}{
+				"x-max-priority": int64(10),
+				"x-message-ttl":  int64(60000),
+				"x-queue-type":   "classic",
+			},
+		}},
+		Bindings:         []Binding{{Queue: "order.created", Exchange: "orders", RoutingKey: "order.created"}},
+		ExchangeBindings: []ExchangeBinding{{Destination: "orders", Source: "legacy", RoutingKey: "#"}},
+	}
+
+	tests := []struct {
+		name  string
+		parse func(data []byte) (*Topology, error)
+		data  string
+	}{
+		{name: "json", parse: FromJSON, data: jsonDefinition},
+		{name: "yaml", parse: FromYAML, data: yamlDefinition},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.parse([]byte(test.data))
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("parsed topology = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package pubsub
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Fallback defines how a Router settles the messages matching no route.
+type Fallback int
+
+const (
+	// FallbackReject rejects the message, so the broker drops it or dead-letters it when the queue has a dead letter
+	// exchange (default).
+	FallbackReject Fallback = iota
+
+	// FallbackDeadLetter publishes the message to the dead letter topic (see WithDeadLetter) then acknowledges it. The
+	// message is requeued when it can't be published, and rejected when no dead letter topic is set.
+	FallbackDeadLetter
+
+	// FallbackAckAndDrop acknowledges and drops the message.
+	FallbackAckAndDrop
+)
+
+// HeaderPredicate matches message headers.
+type HeaderPredicate func(headers map[string]interface{}) bool
+
+// HeaderEquals matches messages whose header has the given value. Values are compared with their type, so integers
+// must be given with the type they are decoded with (e.g. int32 or int64 for RabbitMQ headers).
+func HeaderEquals(key string, value interface{}) HeaderPredicate {
+	return func(headers map[string]interface{}) bool {
+		actual, found := headers[key]
+		return found && reflect.DeepEqual(actual, value)
+	}
+}
+
+// HeaderExists matches messages having the header.
+func HeaderExists(key string) HeaderPredicate {
+	return func(headers map[string]interface{}) bool {
+		_, found := headers[key]
+		return found
+	}
+}
+
+// RouterOption is a router option used to customize the router.
+type RouterOption func(r *Router)
+
+// WithFallback set how the messages matching no route are settled.
+func WithFallback(fallback Fallback) RouterOption {
+	return func(r *Router) {
+		r.fallback = fallback
+	}
+}
+
+// WithDeadLetter publishes the messages matching no route to the topic, keeping their routing key. See
+// FallbackDeadLetter.
+func WithDeadLetter(publisher Publisher, topic string) RouterOption {
+	return func(r *Router) {
+		r.fallback = FallbackDeadLetter
+		r.deadLetterPublisher = publisher
+		r.deadLetterTopic = topic
+	}
+}
+
+// WithFallbackHandler handles the messages matching no route with the given handler, which must settle them.
+func WithFallbackHandler(handler func(message Message)) RouterOption {
+	return func(r *Router) {
+		r.fallbackHandler = handler
+	}
+}
+
+type route struct {
+	match   func(message Message) bool
+	handler func(message Message)
+}
+
+/*
+	Router dispatches the consumed messages of a queue carrying several message types to the handler of the first
+	matching route, in the order the routes were added. Messages matching no route are settled according to the
+	fallback (see WithFallback).
+
+	Routes are meant to be added before subscribing:
+
+		router := pubsub.NewRouter()
+		router.HandleType("order.created", handleOrderCreated)
+		router.HandleRoutingKey("payment.*.failed", handlePaymentFailure)
+		sub.Subscribe(router.Handle)
+*/
+type Router struct {
+	routes              []route
+	fallback            Fallback
+	fallbackHandler     func(message Message)
+	deadLetterPublisher Publisher
+	deadLetterTopic     string
+}
+
+// NewRouter returns a new message router.
+func NewRouter(options ...RouterOption) *Router {
+	router := &Router{}
+	for _, optionFunction := range options {
+		optionFunction(router)
+	}
+
+	return router
+}
+
+// HandleType routes the messages of the given type (see Message.Type) to the handler.
+func (r *Router) HandleType(messageType string, handler func(message Message)) {
+	r.HandleFunc(func(message Message) bool {
+		return message.Type() == messageType
+	}, handler)
+}
+
+// HandleRoutingKey routes the messages whose routing key matches the topic pattern to the handler. Patterns are
+// dot-separated words where "*" matches exactly one word and "#" matches zero or more words, as in topic exchanges.
+func (r *Router) HandleRoutingKey(pattern string, handler func(message Message)) {
+	patternWords := strings.Split(pattern, ".")
+	r.HandleFunc(func(message Message) bool {
+		return matchTopic(patternWords, strings.Split(message.RoutingKey(), "."))
+	}, handler)
+}
+
+// HandleHeaders routes the messages whose headers match all the predicates to the handler.
+func (r *Router) HandleHeaders(handler func(message Message), predicates ...HeaderPredicate) {
+	r.HandleFunc(func(message Message) bool {
+		headers := message.Headers()
+		for _, predicate := range predicates {
+			if !predicate(headers) {
+				return false
+			}
+		}
+
+		return true
+	}, handler)
+}
+
+// HandleFunc routes the messages matched by the given function to the handler.
+func (r *Router) HandleFunc(match func(message Message) bool, handler func(message Message)) {
+	r.routes = append(r.routes, route{match: match, handler: handler})
+}
+
+// Handle dispatches the message to the handler of the first matching route, or settles it according to the fallback.
+// It can be given as handler to Subscriber.Subscribe.
+func (r *Router) Handle(message Message) {
+	for _, route := range r.routes {
+		if route.match(message) {
+			route.handler(message)
+			return
+		}
+	}
+
+	r.handleUnmatched(message)
+}
+
+func (r *Router) handleUnmatched(message Message) {
+	if r.fallbackHandler != nil {
+		r.fallbackHandler(message)
+		return
+	}
+
+	switch {
+	case r.fallback == FallbackDeadLetter && r.deadLetterPublisher != nil:
+		err := r.deadLetterPublisher.Publish(message.Clone(), r.deadLetterTopic, message.RoutingKey())
+		if err != nil {
+			_ = message.Nack()
+			return
+		}
+
+		_ = message.Ack()
+	case r.fallback == FallbackAckAndDrop:
+		_ = message.Ack()
+	default:
+		_ = message.Reject()
+	}
+}
+
+// matchTopic matches the routing key words against the topic pattern words.
+func matchTopic(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(words); i++ {
+			if matchTopic(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+
+		return false
+	case "*":
+		return len(words) > 0 && matchTopic(pattern[1:], words[1:])
+	default:
+		return len(words) > 0 && words[0] == pattern[0] && matchTopic(pattern[1:], words[1:])
+	}
+}
@@ -0,0 +1,52 @@
+package pubsub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		routingKey string
+		want       bool
+	}{
+		{pattern: "order.created", routingKey: "order.created", want: true},
+		{pattern: "order.created", routingKey: "order.deleted", want: false},
+		{pattern: "order.created", routingKey: "order.created.eu", want: false},
+		{pattern: "order.*", routingKey: "order.created", want: true},
+		{pattern: "order.*", routingKey: "order", want: false},
+		{pattern: "order.*", routingKey: "order.created.eu", want: false},
+		{pattern: "*.created", routingKey: "order.created", want: true},
+		{pattern: "*.*", routingKey: "order.created", want: true},
+		{pattern: "*.*", routingKey: "order", want: false},
+		{pattern: "#", routingKey: "order", want: true},
+		{pattern: "#", routingKey: "order.created.eu", want: true},
+		{pattern: "order.#", routingKey: "order", want: true},
+		{pattern: "order.#", routingKey: "order.created", want: true},
+		{pattern: "order.#", routingKey: "order.created.eu", want: true},
+		{pattern: "order.#", routingKey: "invoice.created", want: false},
+		{pattern: "#.created", routingKey: "created", want: true},
+		{pattern: "#.created", routingKey: "order.item.created", want: true},
+		{pattern: "#.created", routingKey: "order.created.eu", want: false},
+		{pattern: "order.#.eu", routingKey: "order.eu", want: true},
+		{pattern: "order.#.eu", routingKey: "order.created.item.eu", want: true},
+		{pattern: "order.#.eu", routingKey: "order.created.us", want: false},
+		{pattern: "#.#", routingKey: "order", want: true},
+		{pattern: "#.*", routingKey: "order", want: true},
+		{pattern: "*.#", routingKey: "order.created.eu", want: true},
+		{pattern: "*.#.*", routingKey: "order", want: false},
+		{pattern: "*.#.*", routingKey: "order.created", want: true},
+		{pattern: "#.order.#", routingKey: "eu.order.created", want: true},
+		{pattern: "#.order.#", routingKey: "eu.invoice.created", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.routingKey, func(t *testing.T) {
+			got := matchTopic(strings.Split(test.pattern, "."), strings.Split(test.routingKey, "."))
+			if got != test.want {
+				t.Errorf("matchTopic(%q, %q) = %v, want %v", test.pattern, test.routingKey, got, test.want)
+			}
+		})
+	}
+}
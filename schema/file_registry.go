@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+/*
+	NewFileRegistry returns a Registry loading the schemas from the directory, laid out as "<type>/<version>.json" for
+	JSON Schemas and "<type>/<version>.binpb" for protobuf descriptor sets (see NewProtoSchema, the message is the one
+	named as the type when it exists). The schema of messages without version header is "<type>/latest.<ext>". Types
+	with a directory but no file for the version are refused with ErrVersionNotFound.
+
+	JSON Schemas may reference other schema files with relative $ref. Loaded schemas are cached, including missing
+	ones, so changes to the directory are only seen by new registries.
+*/
+func NewFileRegistry(dir string) Registry {
+	return &fileRegistry{dir: dir, mutex: &sync.Mutex{}, schemas: map[string]Schema{}, types: map[string]bool{}}
+}
+
+type fileRegistry struct {
+	dir     string
+	mutex   *sync.Mutex
+	schemas map[string]Schema
+	types   map[string]bool
+}
+
+func (r *fileRegistry) Schema(_ context.Context, messageType, version string) (Schema, error) {
+	if !isPlainName(messageType) || !isPlainName(version) {
+		return nil, fmt.Errorf("%w: type %q version %q", ErrInvalidVersion, messageType, version)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := messageType + "/" + version
+	schema, isCached := r.schemas[key]
+	if !isCached {
+		var err error
+		if schema, err = r.load(messageType, version); err != nil {
+			return nil, err
+		}
+
+		r.schemas[key] = schema
+	}
+
+	if schema == nil {
+		return nil, r.notFound(messageType, version)
+	}
+
+	return schema, nil
+}
+
+// notFound returns the error of a missing schema: ErrVersionNotFound when the directory of the message type exists,
+// ErrSchemaNotFound otherwise.
+func (r *fileRegistry) notFound(messageType, version string) error {
+	hasType, isCached := r.types[messageType]
+	if !isCached {
+		info, err := os.Stat(filepath.Join(r.dir, messageType))
+		hasType = err == nil && info.IsDir()
+		r.types[messageType] = hasType
+	}
+
+	if hasType {
+		return fmt.Errorf("%w: type %q version %q", ErrVersionNotFound, messageType, version)
+	}
+
+	return fmt.Errorf("%w: type %q version %q", ErrSchemaNotFound, messageType, version)
+}
+
+// load loads the schema file of the message type and version, returning a nil schema when there is none.
+func (r *fileRegistry) load(messageType, version string) (Schema, error) {
+	path := filepath.Join(r.dir, messageType, version)
+
+	if _, err := os.Stat(path + ".json"); err == nil {
+		schema, err := compileJSONSchema(jsonschema.NewCompiler(), path+".json")
+		if err != nil {
+			return nil, fmt.Errorf("schema: load %s.json: %w", path, err)
+		}
+
+		return schema, nil
+	}
+
+	descriptorSet, err := ioutil.ReadFile(path + ".binpb")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("schema: %w", err)
+	}
+
+	schema, err := NewProtoSchema(descriptorSet, messageType)
+	if err != nil {
+		schema, err = NewProtoSchema(descriptorSet, "")
+	}
+
+	return schema, err
+}
+
+// isPlainName checks the type or version, which come from message properties and headers, can't escape the registry
+// directory.
+func isPlainName(name string) bool {
+	return name != "" && name[0] != '.' && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// NewJSONSchema compiles a JSON Schema document (draft 4 to 2020-12) validating JSON bodies.
+func NewJSONSchema(document []byte) (Schema, error) {
+	const url = "schema.json"
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(document)); err != nil {
+		return nil, err
+	}
+
+	return compileJSONSchema(compiler, url)
+}
+
+func compileJSONSchema(compiler *jsonschema.Compiler, url string) (Schema, error) {
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonSchema{schema: schema}, nil
+}
+
+type jsonSchema struct {
+	schema *jsonschema.Schema
+}
+
+func (s *jsonSchema) Validate(body []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	return s.schema.Validate(value)
+}
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+/*
+	NewProtoSchema returns a schema validating protobuf bodies against the message of the given full name (e.g.
+	"orders.v1.OrderCreated"), described by a serialized FileDescriptorSet (e.g. generated by "protoc
+	--include_imports --descriptor_set_out").
+
+	When messageName is empty, the first message of the last file of the set is used, which is the file given to
+	protoc. Bodies are valid when they can be decoded as the message and have all required fields set.
+*/
+func NewProtoSchema(descriptorSet []byte, messageName string) (Schema, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(descriptorSet, set); err != nil {
+		return nil, fmt.Errorf("schema: parse descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("schema: parse descriptor set: %w", err)
+	}
+
+	if messageName == "" {
+		messageName, err = defaultMessageName(set)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(messageName))
+	if err != nil {
+		return nil, fmt.Errorf("schema: find message %q: %w", messageName, err)
+	}
+
+	message, isMessage := descriptor.(protoreflect.MessageDescriptor)
+	if !isMessage {
+		return nil, fmt.Errorf("schema: %q is not a message", messageName)
+	}
+
+	return &protoSchema{message: message}, nil
+}
+
+func defaultMessageName(set *descriptorpb.FileDescriptorSet) (string, error) {
+	if len(set.File) == 0 || len(set.File[len(set.File)-1].MessageType) == 0 {
+		return "", fmt.Errorf("schema: descriptor set has no message")
+	}
+
+	file := set.File[len(set.File)-1]
+	if file.GetPackage() == "" {
+		return file.MessageType[0].GetName(), nil
+	}
+
+	return file.GetPackage() + "." + file.MessageType[0].GetName(), nil
+}
+
+type protoSchema struct {
+	message protoreflect.MessageDescriptor
+}
+
+func (s *protoSchema) Validate(body []byte) error {
+	return proto.Unmarshal(body, dynamicpb.NewMessage(s.message))
+}
@@ -0,0 +1,78 @@
+// Package schema validates message bodies against the schema registered for their type and version, so invalid
+// payloads are refused on publish and rejected on consume instead of failing deep inside handlers.
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/maykonlf/pubsub"
+)
+
+// VersionHeader is the header carrying the schema version of the message body. Messages without it are validated
+// against the LatestVersion schema.
+const VersionHeader = "x-schema-version"
+
+// LatestVersion is the version used to resolve the schema of messages without version header.
+const LatestVersion = "latest"
+
+// ErrSchemaNotFound is returned by registries when no schema is registered for a message type and version.
+var ErrSchemaNotFound = errors.New("schema: schema not found")
+
+// ErrInvalidMessage is returned when a message body doesn't match its schema.
+var ErrInvalidMessage = errors.New("schema: invalid message")
+
+// ErrVersionNotFound is returned by registries when schemas are registered for the message type, but not for its
+// version. It wraps ErrInvalidMessage, so such messages are refused.
+var ErrVersionNotFound = fmt.Errorf("%w: schema version not found", ErrInvalidMessage)
+
+// ErrInvalidVersion is returned by registries when the message type or version can't name a schema (e.g. contains a
+// path separator). It wraps ErrInvalidMessage, so such messages are refused.
+var ErrInvalidVersion = fmt.Errorf("%w: invalid schema version", ErrInvalidMessage)
+
+// Schema validates message bodies.
+type Schema interface {
+	// Validate returns an error describing why the body doesn't match the schema.
+	Validate(body []byte) error
+}
+
+// Registry resolves the schemas by message type and version.
+type Registry interface {
+	// Schema returns the schema of the message type and version, or an ErrSchemaNotFound wrapped error when the type
+	// has no schema, an ErrVersionNotFound wrapped one when it has no schema for the version.
+	Schema(ctx context.Context, messageType, version string) (Schema, error)
+}
+
+/*
+	Validate validates the message body against the schema registered for its type and version, returning an
+	ErrInvalidMessage wrapped error when it doesn't match.
+
+	Messages without type, or whose type has no registered schema, are considered valid. Messages whose type has
+	registered schemas, but not for their version, are invalid.
+*/
+func Validate(ctx context.Context, registry Registry, message pubsub.Message) error {
+	if message.Type() == "" {
+		return nil
+	}
+
+	version, hasVersion := message.HeaderString(VersionHeader)
+	if !hasVersion {
+		version = LatestVersion
+	}
+
+	schema, err := registry.Schema(ctx, message.Type(), version)
+	if errors.Is(err, ErrSchemaNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := schema.Validate(message.Body()); err != nil {
+		return fmt.Errorf("%w: type %q version %q: %v", ErrInvalidMessage, message.Type(), version, err)
+	}
+
+	return nil
+}
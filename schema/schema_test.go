@@ -0,0 +1,73 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maykonlf/pubsub/rabbitmq"
+	"github.com/maykonlf/pubsub/schema"
+)
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const document = `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}`
+	if err := os.Mkdir(filepath.Join(dir, "order.created"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []string{"latest", "v1"} {
+		path := filepath.Join(dir, "order.created", version+".json")
+		if err := ioutil.WriteFile(path, []byte(document), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		messageType string
+		version     interface{}
+		body        string
+		want        error
+	}{
+		{name: "valid", messageType: "order.created", version: "v1", body: `{"id": 1}`},
+		{name: "valid latest", messageType: "order.created", body: `{"id": 1}`},
+		{name: "invalid", messageType: "order.created", version: "v1", body: `{"id": "1"}`,
+			want: schema.ErrInvalidMessage},
+		{name: "unknown version", messageType: "order.created", version: "v2", body: `{"id": 1}`,
+			want: schema.ErrVersionNotFound},
+		{name: "bad version name", messageType: "order.created", version: "../v1", body: `{"id": 1}`,
+			want: schema.ErrInvalidVersion},
+		{name: "bad type name", messageType: ".order", version: "v1", body: `{"id": 1}`,
+			want: schema.ErrInvalidVersion},
+		{name: "unknown type", messageType: "order.deleted", version: "v1", body: `{}`},
+		{name: "no type", body: `{}`},
+	}
+
+	registry := schema.NewFileRegistry(dir)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := rabbitmq.NewMessage().SetType(test.messageType).SetBody([]byte(test.body))
+			if test.version != nil {
+				message.SetHeader(schema.VersionHeader, test.version)
+			}
+
+			err := schema.Validate(context.Background(), registry, message)
+			if test.want == nil && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+
+			if test.want != nil && (!errors.Is(err, test.want) || !errors.Is(err, schema.ErrInvalidMessage)) {
+				t.Errorf("Validate() error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package pubsub
+
+import "context"
+
+const (
+	// TraceParentHeader is the W3C Trace Context "traceparent" header name.
+	TraceParentHeader = "traceparent"
+
+	// TraceStateHeader is the W3C Trace Context "tracestate" header name.
+	TraceStateHeader = "tracestate"
+)
+
+type traceContextKey struct{}
+
+type traceContext struct {
+	parent string
+	state  string
+}
+
+// ContextWithTraceParent returns a copy of ctx carrying the given W3C trace context, which is propagated into the
+// headers of the messages published with it.
+func ContextWithTraceParent(ctx context.Context, traceParent, traceState string) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext{parent: traceParent, state: traceState})
+}
+
+// TraceParentFromContext returns the W3C trace context carried by ctx, if any.
+func TraceParentFromContext(ctx context.Context) (traceParent, traceState string) {
+	trace, _ := ctx.Value(traceContextKey{}).(traceContext)
+	return trace.parent, trace.state
+}
+
+// ContextWithMessageTrace returns a copy of ctx carrying the W3C trace context found in the message headers, so
+// consumers can continue the trace started by the publisher.
+func ContextWithMessageTrace(ctx context.Context, message Message) context.Context {
+	traceParent, _ := message.GetHeader(TraceParentHeader).(string)
+	if traceParent == "" {
+		return ctx
+	}
+
+	traceState, _ := message.GetHeader(TraceStateHeader).(string)
+	return ContextWithTraceParent(ctx, traceParent, traceState)
+}
+
+// InjectTraceContext writes the W3C trace context carried by ctx into the given headers, returning a copy of them
+// (the given headers are not modified). Headers are returned as is when ctx carries no trace context.
+func InjectTraceContext(ctx context.Context, headers map[string]interface{}) map[string]interface{} {
+	traceParent, traceState := TraceParentFromContext(ctx)
+	if traceParent == "" {
+		return headers
+	}
+
+	injected := make(map[string]interface{}, len(headers)+2)
+	for key, value := range headers {
+		injected[key] = value
+	}
+
+	injected[TraceParentHeader] = traceParent
+	if traceState != "" {
+		injected[TraceStateHeader] = traceState
+	}
+
+	return injected
+}